@@ -0,0 +1,412 @@
+/*
+ * Open Chinese Convert
+ *
+ * Copyright 2010-2014 Carbo Kuo <byvoid@byvoid.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package opencc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/transform"
+
+	"github.com/yanmingcao/opencc-go/pkg/embeddata"
+	"github.com/yanmingcao/opencc-go/pkg/segmentation"
+)
+
+// autoPresets are the presets accepted by NewAutoConverter. Each maps the
+// detected script of a paragraph to the embedded preset that converts it to
+// the target script; scripts without one are left unchanged. Traditional
+// text in Taiwan or Hong Kong forms is handled by t2s, whose dictionary
+// covers those forms too.
+var autoPresets = map[string]map[Script]string{
+	"auto2s":  {ScriptTraditional: "t2s", ScriptMixed: "t2s"},
+	"auto2t":  {ScriptSimplified: "s2t", ScriptMixed: "s2t"},
+	"auto2tw": {ScriptSimplified: "s2tw", ScriptMixed: "s2tw", ScriptTraditional: "t2tw"},
+	"auto2hk": {ScriptSimplified: "s2hk", ScriptMixed: "s2hk", ScriptTraditional: "t2hk"},
+}
+
+// AutoPresets returns the names accepted by NewAutoConverter
+func AutoPresets() []string {
+	names := make([]string, 0, len(autoPresets))
+	for name := range autoPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// autoConverter converts every paragraph with the converter for its
+// detected script
+type autoConverter struct {
+	// presets maps detected scripts to preset names
+	presets map[Script]string
+	// converters maps preset names to their converters
+	converters map[string]*SimpleConverter
+}
+
+// NewAutoConverter creates a converter for an automatic preset such as
+// auto2s or auto2tw, which converts text of any script to one target. The
+// script of every paragraph, up to and including its line break, is
+// detected with Detect and the paragraph is converted with the matching
+// embedded preset, or left unchanged if it is already in the target script.
+func NewAutoConverter(name string) (*SimpleConverter, error) {
+	presets, ok := autoPresets[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown automatic preset: %s", name)
+	}
+
+	auto := &autoConverter{presets: presets, converters: make(map[string]*SimpleConverter)}
+	for _, name := range presets {
+		if _, ok := auto.converters[name]; ok {
+			continue
+		}
+		configData, err := embeddata.GetConfig(name)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", err, name)
+		}
+		converter, err := NewSimpleConverterFromData(configData)
+		if err != nil {
+			return nil, err
+		}
+		auto.converters[name] = converter
+	}
+	return auto.simpleConverter(), nil
+}
+
+// simpleConverter wraps a in a SimpleConverter
+func (a *autoConverter) simpleConverter() *SimpleConverter {
+	return &SimpleConverter{auto: a}
+}
+
+// converterFor returns the converter for paragraph, or nil if it is to be
+// left unchanged
+func (a *autoConverter) converterFor(paragraph string) *Converter {
+	return a.converterForScript(Detect(paragraph).Script)
+}
+
+// converterForScript returns the converter for text of script, or nil if it
+// is to be left unchanged
+func (a *autoConverter) converterForScript(script Script) *Converter {
+	name := a.presets[script]
+	if name == "" {
+		return nil
+	}
+	return a.converters[name].converter
+}
+
+// forEachParagraph calls fn with every paragraph of text and its converter
+func (a *autoConverter) forEachParagraph(text string, fn func(c *Converter, paragraph string)) {
+	for len(text) > 0 {
+		end := strings.IndexByte(text, '\n') + 1
+		if end == 0 {
+			end = len(text)
+		}
+		fn(a.converterFor(text[:end]), text[:end])
+		text = text[end:]
+	}
+}
+
+// convert implements Convert
+func (a *autoConverter) convert(text string) string {
+	var builder strings.Builder
+	a.forEachParagraph(text, func(c *Converter, paragraph string) {
+		if c != nil {
+			paragraph = c.Convert(paragraph)
+		}
+		builder.WriteString(paragraph)
+	})
+	return builder.String()
+}
+
+// convertWithMapping implements ConvertWithMapping. A paragraph left
+// unchanged is aligned as a whole.
+func (a *autoConverter) convertWithMapping(text string) (string, []Alignment) {
+	var builder strings.Builder
+	var alignments []Alignment
+	// offset is the end of the previous paragraph
+	var offset Alignment
+	shift := func(span, by Span) Span {
+		return Span{Start: span.Start + by.End, End: span.End + by.End}
+	}
+	a.forEachParagraph(text, func(c *Converter, paragraph string) {
+		converted := paragraph
+		runes := utf8.RuneCountInString(paragraph)
+		paragraphAlignments := []Alignment{{
+			Source:      Span{End: len(paragraph)},
+			Target:      Span{End: len(paragraph)},
+			SourceRunes: Span{End: runes},
+			TargetRunes: Span{End: runes},
+		}}
+		if c != nil {
+			converted, paragraphAlignments = c.ConvertWithMapping(paragraph)
+		}
+		for _, al := range paragraphAlignments {
+			alignments = append(alignments, Alignment{
+				Source:      shift(al.Source, offset.Source),
+				Target:      shift(al.Target, offset.Target),
+				SourceRunes: shift(al.SourceRunes, offset.SourceRunes),
+				TargetRunes: shift(al.TargetRunes, offset.TargetRunes),
+			})
+		}
+		offset.Source.End += len(paragraph)
+		offset.Target.End += len(converted)
+		offset.SourceRunes.End += runes
+		offset.TargetRunes.End += utf8.RuneCountInString(converted)
+		builder.WriteString(converted)
+	})
+	return builder.String(), alignments
+}
+
+// explain implements Explain. A paragraph left unchanged is explained as a
+// single segment that no step converted.
+func (a *autoConverter) explain(text string) []Explanation {
+	var explanations []Explanation
+	a.forEachParagraph(text, func(c *Converter, paragraph string) {
+		if c == nil {
+			explanations = append(explanations, Explanation{Source: paragraph, Target: paragraph})
+			return
+		}
+		explanations = append(explanations, c.Explain(paragraph)...)
+	})
+	return explanations
+}
+
+// convertCandidates implements ConvertCandidates. A paragraph left
+// unchanged is a single segment with itself as the only candidate.
+func (a *autoConverter) convertCandidates(text string) []SegmentCandidates {
+	var results []SegmentCandidates
+	a.forEachParagraph(text, func(c *Converter, paragraph string) {
+		if c == nil {
+			results = append(results, SegmentCandidates{Source: paragraph, Target: paragraph, Candidates: []string{paragraph}})
+			return
+		}
+		results = append(results, c.ConvertCandidates(paragraph)...)
+	})
+	return results
+}
+
+// convertStream implements ConvertStream. A paragraph longer than the
+// stream chunk size is converted as a stream by the converter chosen from
+// its first chunk that tells its script. The chunks before it, which hold
+// no characters that tell the scripts apart, are copied unchanged, so
+// memory use stays bounded however long a paragraph is.
+func (a *autoConverter) convertStream(ctx context.Context, r io.Reader, w io.Writer) error {
+	reader := bufio.NewReaderSize(r, streamChunkSize)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		data, err := reader.Peek(streamChunkSize)
+		if i := bytes.IndexByte(data, '\n'); i >= 0 || err != nil {
+			// A whole paragraph, or the rest of the input
+			if i >= 0 {
+				data = data[:i+1]
+			}
+			paragraph := string(data)
+			if c := a.converterFor(paragraph); c != nil {
+				paragraph = c.Convert(paragraph)
+			}
+			if _, err := io.WriteString(w, paragraph); err != nil {
+				return err
+			}
+			reader.Discard(len(data))
+			if i < 0 {
+				if err == io.EOF {
+					return nil
+				}
+				return err
+			}
+			continue
+		}
+
+		script := Detect(string(data)).Script
+		if script == ScriptNeither {
+			n := completeChars(data)
+			if _, err := w.Write(data[:n]); err != nil {
+				return err
+			}
+			reader.Discard(n)
+			continue
+		}
+		rest := &paragraphReader{r: reader}
+		if c := a.converterForScript(script); c == nil {
+			_, err = io.Copy(w, rest)
+		} else {
+			err = c.ConvertStream(ctx, rest, w)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// completeChars returns the length of data without an incomplete character
+// at its end
+func completeChars(data []byte) int {
+	for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(data[i]) {
+			if !utf8.FullRune(data[i:]) {
+				return i
+			}
+			break
+		}
+	}
+	return len(data)
+}
+
+// paragraphReader reads from r up to and including the next line break
+type paragraphReader struct {
+	r    *bufio.Reader
+	done bool
+}
+
+func (p *paragraphReader) Read(b []byte) (int, error) {
+	if p.done {
+		return 0, io.EOF
+	}
+	if p.r.Buffered() == 0 {
+		if _, err := p.r.Peek(1); err != nil {
+			return 0, err
+		}
+	}
+	data, _ := p.r.Peek(min(len(b), p.r.Buffered()))
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		data = data[:i+1]
+		p.done = true
+	}
+	n := copy(b, data)
+	p.r.Discard(n)
+	return n, nil
+}
+
+// autoTransformer implements Transformer for automatic presets. Like
+// convertStream, it chooses the converter of a paragraph from the whole
+// paragraph, or from its first chunk that tells its script if it is longer
+// than the stream chunk size, copying the chunks before it unchanged.
+type autoTransformer struct {
+	auto *autoConverter
+	// buf holds the input of the current paragraph not yet transformed, at
+	// most streamChunkSize bytes
+	buf []byte
+	// chosen is set once the converter of the current paragraph is chosen
+	chosen bool
+	// current transforms the current paragraph, or is nil to copy it
+	current *Transformer
+	// ended is set once buf holds the end of the current paragraph
+	ended bool
+}
+
+// Reset implements transform.Transformer
+func (t *autoTransformer) Reset() {
+	*t = autoTransformer{auto: t.auto, buf: t.buf[:0]}
+}
+
+// Transform implements transform.Transformer. Input is consumed into an
+// internal buffer until the converter of its paragraph is chosen.
+func (t *autoTransformer) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	for {
+		if !t.ended {
+			input := src[nSrc:]
+			if room := max(0, streamChunkSize-len(t.buf)); len(input) > room {
+				input = input[:room]
+			}
+			if i := bytes.IndexByte(input, '\n'); i >= 0 {
+				input = input[:i+1]
+				t.ended = true
+			}
+			t.buf = append(t.buf, input...)
+			nSrc += len(input)
+			t.ended = t.ended || atEOF && nSrc == len(src)
+		}
+		if len(t.buf) == 0 {
+			t.ended = false
+			return nDst, nSrc, nil
+		}
+
+		if !t.chosen {
+			if !t.ended && len(t.buf) < streamChunkSize {
+				return nDst, nSrc, nil
+			}
+			script := Detect(string(t.buf)).Script
+			if script == ScriptNeither && !t.ended {
+				// Copy the start of the paragraph rather than hold it until
+				// its script shows
+				complete := completeChars(t.buf)
+				n := copy(dst[nDst:], t.buf[:complete])
+				nDst += n
+				t.buf = append(t.buf[:0], t.buf[n:]...)
+				if n < complete {
+					return nDst, nSrc, transform.ErrShortDst
+				}
+				continue
+			}
+			t.current = nil
+			if c := t.auto.converterForScript(script); c != nil {
+				t.current = NewTransformer(c)
+			}
+			t.chosen = true
+		}
+
+		var n, m int
+		if t.current == nil {
+			n = copy(dst[nDst:], t.buf)
+			m = n
+			if m < len(t.buf) {
+				err = transform.ErrShortDst
+			}
+		} else {
+			n, m, err = t.current.Transform(dst[nDst:], t.buf, t.ended)
+		}
+		nDst += n
+		t.buf = append(t.buf[:0], t.buf[m:]...)
+		if err == transform.ErrShortDst {
+			return nDst, nSrc, err
+		}
+		if len(t.buf) == 0 && t.ended {
+			t.chosen, t.current, t.ended = false, nil, false
+			continue
+		}
+		if nSrc == len(src) {
+			// The rest of the paragraph is still to come
+			return nDst, nSrc, nil
+		}
+	}
+}
+
+// withConverters returns a copy of a with every converter replaced by fn
+func (a *autoConverter) withConverters(fn func(s *SimpleConverter) *SimpleConverter) *autoConverter {
+	converters := make(map[string]*SimpleConverter, len(a.converters))
+	for name, converter := range a.converters {
+		converters[name] = fn(converter)
+	}
+	return &autoConverter{presets: a.presets, converters: converters}
+}
+
+// setProtector sets the protector of every converter
+func (a *autoConverter) setProtector(p *segmentation.Protector) {
+	for _, converter := range a.converters {
+		converter.SetProtector(p)
+	}
+}
@@ -0,0 +1,166 @@
+/*
+ * Open Chinese Convert
+ *
+ * Copyright 2010-2014 Carbo Kuo <byvoid@byvoid.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package opencc
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/transform"
+
+	"github.com/yanmingcao/opencc-go/pkg/dict"
+)
+
+func TestAutoConverter(t *testing.T) {
+	input := "这是简体中文的段落，我们来看看。\n" +
+		"這是繁體中文的段落，我們來看看。\r\n" +
+		"\n" +
+		"hello"
+
+	converter, err := NewAutoConverter("auto2s")
+	require.NoError(t, err)
+	assert.Equal(t, "这是简体中文的段落，我们来看看。\n"+
+		"这是繁体中文的段落，我们来看看。\r\n"+
+		"\n"+
+		"hello", converter.Convert(input))
+	// Paragraphs already in the target script are left alone
+	assert.Nil(t, converter.auto.converterFor("这是简体中文的段落，我们来看看。\n"))
+
+	converter, err = NewAutoConverter("auto2tw")
+	require.NoError(t, err)
+	assert.Equal(t, "這是簡體中文的段落，我們來看看。\n"+
+		"這是繁體中文的段落，我們來看看。\r\n"+
+		"\n"+
+		"hello", converter.Convert(input))
+
+	// User dictionaries apply to every converter
+	lexicon := dict.NewLexicon()
+	lexicon.Add(dict.NewStrSingleValueDictEntry("段落", "段兒"))
+	lexicon.Sort()
+	custom := converter.WithUserDict(dict.NewTrieDict(lexicon), 1)
+	assert.Equal(t, "這是簡體中文的段兒\n這是繁體中文的段兒", custom.Convert("这是简体中文的段落\n這是繁體中文的段落"))
+
+	_, err = NewAutoConverter("s2t")
+	assert.ErrorContains(t, err, "unknown automatic preset")
+	assert.Equal(t, []string{"auto2hk", "auto2s", "auto2t", "auto2tw"}, AutoPresets())
+}
+
+func TestAutoConverterMapping(t *testing.T) {
+	converter, err := NewAutoConverter("auto2t")
+	require.NoError(t, err)
+	input := "這是繁體。\n简体汉字\n"
+
+	converted, alignments := converter.ConvertWithMapping(input)
+	assert.Equal(t, converter.Convert(input), converted)
+	require.NotEmpty(t, alignments)
+	// The unchanged paragraph is aligned as a whole
+	assert.Equal(t, Span{0, len("這是繁體。\n")}, alignments[0].Source)
+	for i := 1; i < len(alignments); i++ {
+		assert.Equal(t, alignments[i-1].Source.End, alignments[i].Source.Start)
+		assert.Equal(t, alignments[i-1].Target.End, alignments[i].Target.Start)
+	}
+	last := alignments[len(alignments)-1]
+	assert.Equal(t, len(input), last.Source.End)
+	assert.Equal(t, len(converted), last.Target.End)
+
+	explanations := converter.Explain(input)
+	assert.Equal(t, "這是繁體。\n", explanations[0].Source)
+	assert.Empty(t, explanations[0].Steps)
+	candidates := converter.ConvertCandidates(input)
+	assert.Equal(t, []string{"這是繁體。\n"}, candidates[0].Candidates)
+}
+
+func TestAutoConverterStream(t *testing.T) {
+	converter, err := NewAutoConverter("auto2s")
+	require.NoError(t, err)
+
+	tests := map[string]string{
+		"paragraphs": strings.Repeat("这是简体中文。\n這是繁體中文。\n", 100),
+		// Paragraphs longer than a stream chunk
+		"long paragraphs": strings.Repeat("這是繁體中文。", 1<<13) + "\n" + strings.Repeat("这是简体中文。", 1<<13),
+		// A paragraph whose first chunks tell nothing about its script
+		"late script": "<html>" + strings.Repeat("a", 150000) + "這裡的軟體\n這裡",
+	}
+	for name, input := range tests {
+		t.Run(name, func(t *testing.T) {
+			var out bytes.Buffer
+			reader := iotest.HalfReader(strings.NewReader(input))
+			require.NoError(t, converter.ConvertStream(context.Background(), reader, &out))
+			assert.True(t, converter.Convert(input) == out.String())
+		})
+	}
+}
+
+func TestAutoConverterTransformer(t *testing.T) {
+	converter, err := NewAutoConverter("auto2s")
+	require.NoError(t, err)
+	assert.Nil(t, converter.GetConverter())
+
+	tests := map[string]string{
+		"paragraphs":      strings.Repeat("这是简体中文。\n這是繁體中文。\n", 100),
+		"long paragraphs": strings.Repeat("這是繁體中文。", 1<<13) + "\n" + strings.Repeat("这是简体中文。", 1<<13),
+		"late script":     "<html>" + strings.Repeat("a", 150000) + "這裡的軟體\n這裡",
+	}
+	for name, input := range tests {
+		t.Run(name, func(t *testing.T) {
+			expected := converter.Convert(input)
+
+			result, n, err := transform.String(converter.Transformer(), input)
+			require.NoError(t, err)
+			assert.Equal(t, len(input), n)
+			assert.True(t, expected == result)
+
+			reader := transform.NewReader(iotest.HalfReader(strings.NewReader(input)), converter.Transformer())
+			output, err := io.ReadAll(reader)
+			require.NoError(t, err)
+			assert.True(t, expected == string(output))
+		})
+	}
+}
+
+func TestAutoConverterLongLineWithoutHan(t *testing.T) {
+	converter, err := NewAutoConverter("auto2s")
+	require.NoError(t, err)
+
+	// No script shows until the end of a line longer than any buffer
+	input := strings.Repeat("abc ", 1<<19) + "這裡\n"
+	expected := converter.Convert(input)
+
+	reader := &countingReader{r: strings.NewReader(input)}
+	out := &progressWriter{input: reader, mark: streamChunkSize}
+	require.NoError(t, converter.ConvertStream(context.Background(), reader, out))
+	assert.Less(t, out.read, len(input)/2)
+	assert.True(t, expected == out.out.String())
+
+	reader = &countingReader{r: strings.NewReader(input)}
+	transformed := transform.NewReader(reader, converter.Transformer())
+	head := make([]byte, streamChunkSize)
+	_, err = io.ReadFull(transformed, head)
+	require.NoError(t, err)
+	assert.Less(t, reader.n, len(input)/2)
+	rest, err := io.ReadAll(transformed)
+	require.NoError(t, err)
+	assert.True(t, expected == string(head)+string(rest))
+}
@@ -0,0 +1,89 @@
+/*
+ * Open Chinese Convert
+ *
+ * Copyright 2010-2014 Carbo Kuo <byvoid@byvoid.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package opencc
+
+// SegmentCandidates lists the possible conversions of one segment
+type SegmentCandidates struct {
+	Source string `json:"source"`
+	// Target is the default conversion, as returned by Convert
+	Target string `json:"target"`
+	// Candidates are the possible conversions after the last step, default
+	// first
+	Candidates []string `json:"candidates"`
+	// Steps holds the candidates after each step of the conversion chain
+	Steps [][]string `json:"steps"`
+}
+
+// ConvertCandidates converts the input text and returns every segment with
+// all of its candidate conversions. Each step of the chain is applied to
+// every candidate of the previous step, so alternatives are carried through
+// the whole chain.
+func (c *Converter) ConvertCandidates(text string) []SegmentCandidates {
+	segments := c.segment(text)
+	converted := c.conversionChain.Convert(segments)
+	conversions := c.conversionChain.GetConversions()
+	results := make([]SegmentCandidates, segments.Length())
+	for i := range results {
+		candidates := []string{segments.At(i)}
+		steps := make([][]string, 0, len(conversions))
+		for _, conversion := range conversions {
+			if segments.IsProtected(i) {
+				steps = append(steps, candidates)
+				continue
+			}
+			var next []string
+			seen := make(map[string]bool)
+			for _, candidate := range candidates {
+				for _, value := range conversion.Candidates(candidate) {
+					if !seen[value] {
+						seen[value] = true
+						next = append(next, value)
+					}
+				}
+			}
+			candidates = next
+			steps = append(steps, candidates)
+		}
+		// A disambiguator may have chosen other than the default
+		target := converted.At(i)
+		for j, candidate := range candidates {
+			if candidate == target && j > 0 {
+				reordered := append([]string{target}, candidates[:j]...)
+				candidates = append(reordered, candidates[j+1:]...)
+				break
+			}
+		}
+		results[i] = SegmentCandidates{
+			Source:     segments.At(i),
+			Target:     target,
+			Candidates: candidates,
+			Steps:      steps,
+		}
+	}
+	return results
+}
+
+// ConvertCandidates converts the input text and returns every segment with
+// all of its candidate conversions
+func (s *SimpleConverter) ConvertCandidates(text string) []SegmentCandidates {
+	if s.auto != nil {
+		return s.auto.convertCandidates(text)
+	}
+	return s.converter.ConvertCandidates(text)
+}
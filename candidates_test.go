@@ -0,0 +1,68 @@
+/*
+ * Open Chinese Convert
+ *
+ * Copyright 2010-2014 Carbo Kuo <byvoid@byvoid.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package opencc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yanmingcao/opencc-go/pkg/conversion"
+	"github.com/yanmingcao/opencc-go/pkg/dict"
+	"github.com/yanmingcao/opencc-go/pkg/segmentation"
+)
+
+func TestConvertCandidates(t *testing.T) {
+	st := dict.NewLexicon()
+	st.Add(dict.NewStrMultiValueDictEntry("发", []string{"發", "髮"}))
+	st.Add(dict.NewStrSingleValueDictEntry("头发", "頭髮"))
+	st.Sort()
+	variants := dict.NewLexicon()
+	variants.Add(dict.NewStrMultiValueDictEntry("髮", []string{"髪", "髮"}))
+	variants.Sort()
+
+	stDict := dict.NewTextDict(st)
+	chain := conversion.NewConversionChain([]*conversion.Conversion{
+		conversion.NewConversion(stDict),
+		conversion.NewConversion(dict.NewTextDict(variants)),
+	})
+	converter := NewConverter("test", segmentation.NewMaxMatchSegmentation(stDict), chain)
+
+	results := converter.ConvertCandidates("发x头发")
+	require.Len(t, results, 3)
+
+	assert.Equal(t, "发", results[0].Source)
+	assert.Equal(t, "發", results[0].Target)
+	assert.Equal(t, [][]string{{"發", "髮"}, {"發", "髪", "髮"}}, results[0].Steps)
+	assert.Equal(t, []string{"發", "髪", "髮"}, results[0].Candidates)
+
+	assert.Equal(t, []string{"x"}, results[1].Candidates)
+
+	assert.Equal(t, "頭髮", results[2].Target)
+	assert.Equal(t, []string{"頭髮"}, results[2].Candidates)
+
+	var targets string
+	for _, r := range results {
+		targets += r.Target
+	}
+	assert.Equal(t, converter.Convert("发x头发"), targets)
+
+	assert.Empty(t, converter.ConvertCandidates(""))
+}
@@ -0,0 +1,390 @@
+/*
+ * Open Chinese Convert
+ *
+ * Copyright 2010-2014 Carbo Kuo <byvoid@byvoid.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yanmingcao/opencc-go"
+)
+
+// binarySniffLength is how much of a file is searched for NUL bytes to tell
+// binary files from text
+const binarySniffLength = 8000
+
+// skippedDirs are version control directories that are never converted
+var skippedDirs = map[string]bool{".git": true, ".hg": true, ".svn": true}
+
+// errNotText reports a file that is binary or not valid in its encoding
+var errNotText = errors.New("not text")
+
+// batchOptions configures the conversion of a directory tree
+type batchOptions struct {
+	// source is the root of the tree to convert
+	source string
+	// output is the directory the converted tree is written to, unless
+	// inPlace is set
+	output  string
+	inPlace bool
+	// backupSuffix, if set, keeps the original of every file changed in
+	// place under its name with the suffix appended
+	backupSuffix string
+	// include and exclude are glob patterns matched against the file name,
+	// or against the slash-separated path relative to source if they
+	// contain a slash. Excluded directories are not entered.
+	include []string
+	exclude []string
+	// extensions, if set, restrict conversion to files with one of these
+	// extensions
+	extensions []string
+	jobs       int
+	encodings  encodingOptions
+}
+
+// batchResult counts the outcome of a batch conversion
+type batchResult struct {
+	converted int
+	unchanged int
+	// skipped counts the files that are not text, which are copied
+	// unchanged to the output directory
+	skipped int
+	// copied counts the files not selected by the filters that were
+	// copied unchanged to the output directory
+	copied int
+	errs   []error
+}
+
+// convertTree converts every matching text file below opts.source, with
+// opts.jobs files converted in parallel. Without inPlace, the other files
+// that are not excluded are copied unchanged to opts.output.
+func convertTree(converter *opencc.SimpleConverter, opts batchOptions) batchResult {
+	var result batchResult
+	files, others, err := collectFiles(opts)
+	if err != nil {
+		result.errs = append(result.errs, err)
+		return result
+	}
+
+	// treeFile is a file to convert, or to copy if copy is set
+	type treeFile struct {
+		rel  string
+		copy bool
+	}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	queue := make(chan treeFile)
+	for i := 0; i < max(opts.jobs, 1); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for file := range queue {
+				if file.copy {
+					err := copyTreeFile(opts, file.rel)
+					mu.Lock()
+					if err != nil {
+						result.errs = append(result.errs, err)
+					} else {
+						result.copied++
+					}
+					mu.Unlock()
+					continue
+				}
+
+				changed, err := convertTreeFile(converter, opts, file.rel)
+				mu.Lock()
+				switch {
+				case errors.Is(err, errNotText):
+					result.skipped++
+				case err != nil:
+					result.errs = append(result.errs, err)
+				case changed:
+					result.converted++
+				default:
+					result.unchanged++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, rel := range files {
+		queue <- treeFile{rel: rel}
+	}
+	for _, rel := range others {
+		queue <- treeFile{rel: rel, copy: true}
+	}
+	close(queue)
+	wg.Wait()
+	return result
+}
+
+// collectFiles lists the regular files to convert and, without inPlace,
+// the other files that are not excluded, relative to opts.source
+func collectFiles(opts batchOptions) (files, others []string, err error) {
+	// Do not descend into the output directory if it is inside the source
+	var outputDir string
+	if !opts.inPlace {
+		outputDir, _ = filepath.Abs(opts.output)
+	}
+
+	err = filepath.WalkDir(opts.source, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(opts.source, path)
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if rel == "." {
+				return nil
+			}
+			abs, _ := filepath.Abs(path)
+			if skippedDirs[entry.Name()] || abs == outputDir || matchAny(opts.exclude, rel) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !entry.Type().IsRegular() || matchAny(opts.exclude, rel) {
+			return nil
+		}
+		if selected(opts, rel) {
+			files = append(files, rel)
+		} else if !opts.inPlace {
+			others = append(others, rel)
+		}
+		return nil
+	})
+	return files, others, err
+}
+
+// selected reports whether the filters of opts select the file at rel
+func selected(opts batchOptions, rel string) bool {
+	if matchAny(opts.exclude, rel) {
+		return false
+	}
+	// Backups of an earlier run are not converted again
+	if opts.inPlace && opts.backupSuffix != "" && strings.HasSuffix(rel, opts.backupSuffix) {
+		return false
+	}
+	if len(opts.include) > 0 && !matchAny(opts.include, rel) {
+		return false
+	}
+	if len(opts.extensions) > 0 {
+		ext := strings.TrimPrefix(filepath.Ext(rel), ".")
+		for _, e := range opts.extensions {
+			if strings.EqualFold(ext, strings.TrimPrefix(e, ".")) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// matchAny reports whether rel matches any of the glob patterns
+func matchAny(patterns []string, rel string) bool {
+	rel = filepath.ToSlash(rel)
+	for _, pattern := range patterns {
+		name := rel
+		if !strings.Contains(pattern, "/") {
+			name = rel[strings.LastIndex(rel, "/")+1:]
+		}
+		if matched, _ := filepath.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// convertTreeFile converts one file of the tree and reports whether the
+// converted content differs from the original
+func convertTreeFile(converter *opencc.SimpleConverter, opts batchOptions, rel string) (bool, error) {
+	source := filepath.Join(opts.source, rel)
+	info, err := os.Stat(source)
+	if err != nil {
+		return false, err
+	}
+	data, err := os.ReadFile(source)
+	if err != nil {
+		return false, err
+	}
+	text, hadBOM, err := decodeText(data, opts.encodings.from)
+	if err != nil {
+		if !opts.inPlace {
+			if err := writeTreeFile(opts, rel, data, info); err != nil {
+				return false, err
+			}
+		}
+		return false, fmt.Errorf("%w: %s", err, source)
+	}
+	converted, err := encodeText(converter.Convert(text), opts.encodings.to, hadBOM)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", source, err)
+	}
+	changed := !bytes.Equal(converted, data)
+	if !opts.inPlace {
+		return changed, writeTreeFile(opts, rel, converted, info)
+	}
+	if !changed {
+		return false, nil
+	}
+	if err := writeFileAtomic(source, converted, info, opts.backupSuffix); err != nil {
+		return false, fmt.Errorf("cannot write %s: %w", source, err)
+	}
+	return true, nil
+}
+
+// copyTreeFile copies a file of the tree unchanged to the output directory
+func copyTreeFile(opts batchOptions, rel string) error {
+	source := filepath.Join(opts.source, rel)
+	info, err := os.Stat(source)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(source)
+	if err != nil {
+		return err
+	}
+	return writeTreeFile(opts, rel, data, info)
+}
+
+// writeTreeFile writes data to the output directory as the file at rel,
+// with the permissions and modification time of info
+func writeTreeFile(opts batchOptions, rel string, data []byte, info os.FileInfo) error {
+	target := filepath.Join(opts.output, rel)
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	if err := writeFileAtomic(target, data, info, ""); err != nil {
+		return fmt.Errorf("cannot write %s: %w", target, err)
+	}
+	return nil
+}
+
+// writeFileAtomic replaces the file at path with data, with the permissions
+// and modification time of info. The file is written next to path and
+// renamed over it, so readers never see it half written. If backupSuffix is
+// set, an existing file is kept under its name with the suffix appended;
+// the backup is a hard link, or a copy where links are not supported, so
+// path exists throughout. An existing backup is never overwritten.
+func writeFileAtomic(path string, data []byte, info os.FileInfo, backupSuffix string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode().Perm()); err != nil {
+		return err
+	}
+	if err := os.Chtimes(tmp.Name(), time.Time{}, info.ModTime()); err != nil {
+		return err
+	}
+
+	if backupSuffix != "" {
+		if err := backupFile(path, path+backupSuffix); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// backupFile links path to backup, or copies it if the link fails, and
+// fails if backup already exists
+func backupFile(path, backup string) error {
+	err := os.Link(path, backup)
+	if errors.Is(err, fs.ErrExist) {
+		return fmt.Errorf("backup %s already exists", backup)
+	}
+	if err == nil || errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(backup, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if errors.Is(err, fs.ErrExist) {
+		return fmt.Errorf("backup %s already exists", backup)
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(backup)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(backup)
+		return err
+	}
+	return os.Chtimes(backup, time.Time{}, info.ModTime())
+}
+
+// runBatch converts a directory tree for the main command and returns the
+// exit code. conflicting reports options that only apply to single inputs.
+func runBatch(converter *opencc.SimpleConverter, opts batchOptions, conflicting bool) int {
+	switch {
+	case conflicting:
+		fmt.Fprintf(os.Stderr, "Error: -r cannot be combined with -i or --candidates\n")
+		return 1
+	case opts.inPlace == (opts.output != ""):
+		fmt.Fprintf(os.Stderr, "Error: -r needs exactly one of -o <dir> and --in-place\n")
+		return 1
+	case opts.backupSuffix != "" && !opts.inPlace:
+		fmt.Fprintf(os.Stderr, "Error: --backup needs --in-place\n")
+		return 1
+	}
+
+	result := convertTree(converter, opts)
+	for _, err := range result.errs {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	}
+	if opts.inPlace {
+		fmt.Fprintf(os.Stderr, "%d converted, %d unchanged, %d skipped as not text\n", result.converted, result.unchanged, result.skipped)
+	} else {
+		fmt.Fprintf(os.Stderr, "%d converted, %d unchanged, %d copied as not text, %d copied as not selected\n",
+			result.converted, result.unchanged, result.skipped, result.copied)
+	}
+	if len(result.errs) > 0 {
+		return 1
+	}
+	return 0
+}
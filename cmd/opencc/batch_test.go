@@ -0,0 +1,155 @@
+/*
+ * Open Chinese Convert
+ *
+ * Copyright 2010-2014 Carbo Kuo <byvoid@byvoid.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTree(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestConvertTree(t *testing.T) {
+	converter, err := newConverter("s2t")
+	require.NoError(t, err)
+	source := t.TempDir()
+	writeTree(t, source, map[string]string{
+		"a.md":            "\uFEFF简体汉字\r\n",
+		"docs/b.txt":      "汉字",
+		"docs/same.txt":   "abc",
+		"img/c.png":       "\x89PNG\x00汉字",
+		"gbk.txt":         "\xba\xba\xd7\xd6",
+		".git/config":     "汉字",
+		"vendor/d.md":     "汉字",
+		"docs/draft.md":   "汉字",
+		"docs/notes.html": "汉字",
+	})
+	mtime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	for _, name := range []string{"a.md", "gbk.txt"} {
+		require.NoError(t, os.Chmod(filepath.Join(source, name), 0600))
+		require.NoError(t, os.Chtimes(filepath.Join(source, name), mtime, mtime))
+	}
+
+	output := filepath.Join(source, "out")
+	result := convertTree(converter, batchOptions{
+		source:     source,
+		output:     output,
+		exclude:    []string{"vendor", "docs/draft.md"},
+		extensions: []string{"md", ".txt", "png"},
+		jobs:       4,
+		encodings:  utf8Encodings,
+	})
+	require.Empty(t, result.errs)
+	assert.Equal(t, batchResult{converted: 2, unchanged: 1, skipped: 2, copied: 1}, result)
+
+	assert.Equal(t, "\uFEFF簡體漢字\r\n", readFile(t, filepath.Join(output, "a.md")))
+	assert.Equal(t, "漢字", readFile(t, filepath.Join(output, "docs", "b.txt")))
+	assert.Equal(t, "abc", readFile(t, filepath.Join(output, "docs", "same.txt")))
+	// Files that are not text or not selected are copied as they are
+	assert.Equal(t, "\x89PNG\x00汉字", readFile(t, filepath.Join(output, "img", "c.png")))
+	assert.Equal(t, "\xba\xba\xd7\xd6", readFile(t, filepath.Join(output, "gbk.txt")))
+	assert.Equal(t, "汉字", readFile(t, filepath.Join(output, "docs", "notes.html")))
+	for _, name := range []string{".git/config", "vendor/d.md", "docs/draft.md"} {
+		assert.NoFileExists(t, filepath.Join(output, filepath.FromSlash(name)))
+	}
+
+	for _, name := range []string{"a.md", "gbk.txt"} {
+		info, err := os.Stat(filepath.Join(output, name))
+		require.NoError(t, err)
+		assert.True(t, info.ModTime().Equal(mtime))
+		if runtime.GOOS != "windows" {
+			assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+		}
+	}
+
+	// Running again does not descend into the output directory
+	result = convertTree(converter, batchOptions{source: source, output: output, include: []string{"*.md"}, encodings: utf8Encodings})
+	require.Empty(t, result.errs)
+	assert.Equal(t, 3, result.converted+result.unchanged)
+}
+
+func TestConvertTreeInPlace(t *testing.T) {
+	converter, err := newConverter("s2t")
+	require.NoError(t, err)
+	source := t.TempDir()
+	writeTree(t, source, map[string]string{"a.md": "汉字", "b.md": "abc"})
+
+	opts := batchOptions{source: source, inPlace: true, backupSuffix: ".bak", jobs: 2, encodings: utf8Encodings}
+	result := convertTree(converter, opts)
+	require.Empty(t, result.errs)
+	assert.Equal(t, batchResult{converted: 1, unchanged: 1}, result)
+	assert.Equal(t, "漢字", readFile(t, filepath.Join(source, "a.md")))
+	assert.Equal(t, "汉字", readFile(t, filepath.Join(source, "a.md.bak")))
+	// Unchanged files are neither rewritten nor backed up
+	assert.NoFileExists(t, filepath.Join(source, "b.md.bak"))
+
+	// Backups are not converted on the next run
+	result = convertTree(converter, opts)
+	assert.Equal(t, batchResult{unchanged: 2}, result)
+	assert.Equal(t, "汉字", readFile(t, filepath.Join(source, "a.md.bak")))
+
+	entries, err := os.ReadDir(source)
+	require.NoError(t, err)
+	assert.Len(t, entries, 3, "no temporary files are left behind")
+
+	// An existing backup is not overwritten
+	writeTree(t, source, map[string]string{"a.md": "简体"})
+	result = convertTree(converter, opts)
+	require.Len(t, result.errs, 1)
+	assert.Contains(t, result.errs[0].Error(), "already exists")
+	assert.Equal(t, "简体", readFile(t, filepath.Join(source, "a.md")))
+	assert.Equal(t, "汉字", readFile(t, filepath.Join(source, "a.md.bak")))
+}
+
+func TestBackupFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.md")
+	writeTree(t, dir, map[string]string{"a.md": "汉字"})
+
+	require.NoError(t, backupFile(path, path+".bak"))
+	assert.Equal(t, "汉字", readFile(t, path+".bak"))
+	assert.Error(t, backupFile(path, path+".bak"))
+	assert.ErrorIs(t, backupFile(filepath.Join(dir, "missing"), path+".orig"), fs.ErrNotExist)
+}
+
+func TestMatchAny(t *testing.T) {
+	assert.True(t, matchAny([]string{"*.md"}, filepath.Join("docs", "a.md")))
+	assert.True(t, matchAny([]string{"docs/*.md"}, filepath.Join("docs", "a.md")))
+	assert.False(t, matchAny([]string{"docs/*.md"}, filepath.Join("other", "a.md")))
+	assert.False(t, matchAny(nil, "a.md"))
+}
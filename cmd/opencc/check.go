@@ -0,0 +1,203 @@
+/*
+ * Open Chinese Convert
+ *
+ * Copyright 2010-2014 Carbo Kuo <byvoid@byvoid.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/yanmingcao/opencc-go"
+)
+
+func checkUsage() {
+	fmt.Fprintf(os.Stderr, "Usage: opencc check -c <preset|config-file> [options] [file...]\n\n")
+	fmt.Fprintf(os.Stderr, "Reports every span of the files that the conversion would change, with\n")
+	fmt.Fprintf(os.Stderr, "the dictionary entries responsible. Stdin is checked if no file is given.\n")
+	fmt.Fprintf(os.Stderr, "Exits with status 1 if --fail-on-change is set and anything would change,\n")
+	fmt.Fprintf(os.Stderr, "and with status 2 if a file cannot be checked.\n\n")
+	fmt.Fprintf(os.Stderr, "Options:\n")
+	fmt.Fprintf(os.Stderr, "  -c, --config <preset|file>  Conversion preset (e.g., tw2t) or config file path\n")
+	fmt.Fprintf(os.Stderr, "  --fail-on-change            Exit with status 1 if anything would change\n")
+	fmt.Fprintf(os.Stderr, "  --json                      Print the changes as a JSON array\n")
+	fmt.Fprintf(os.Stderr, "  --from-encoding <enc>       Encoding of the files, or auto to detect it (default: utf-8)\n")
+}
+
+// runCheck implements the "opencc check" subcommand
+func runCheck(args []string) int {
+	flags := flag.NewFlagSet("check", flag.ContinueOnError)
+	flags.Usage = checkUsage
+	var configFile string
+	flags.StringVar(&configFile, "c", "", "Conversion preset or config file")
+	flags.StringVar(&configFile, "config", "", "Conversion preset or config file")
+	failOnChange := flags.Bool("fail-on-change", false, "Exit with status 1 if anything would change")
+	asJSON := flags.Bool("json", false, "Print the changes as a JSON array")
+	fromEnc := flags.String("from-encoding", "utf-8", "Encoding of the files, or auto to detect it")
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+	if configFile == "" {
+		fmt.Fprintf(os.Stderr, "Error: Conversion preset is required (-c or --config)\n\n")
+		checkUsage()
+		return 2
+	}
+	from, err := lookupEncoding(*fromEnc, true)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+
+	converter, err := newConverter(configFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+
+	files := flags.Args()
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+	changes := []checkChange{}
+	failed := false
+	for _, file := range files {
+		fileChanges, err := checkFile(converter, file, from)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			failed = true
+			continue
+		}
+		changes = append(changes, fileChanges...)
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(changes); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 2
+		}
+	} else {
+		writeChanges(os.Stdout, changes)
+	}
+
+	switch {
+	case failed:
+		return 2
+	case *failOnChange && len(changes) > 0:
+		if len(changes) == 1 {
+			fmt.Fprintf(os.Stderr, "1 span would change\n")
+		} else {
+			fmt.Fprintf(os.Stderr, "%d spans would change\n", len(changes))
+		}
+		return 1
+	}
+	return 0
+}
+
+// checkChange is a span of a file that the conversion would change
+type checkChange struct {
+	File string `json:"file"`
+	// Line and Column are 1-based; the column counts characters
+	Line      int    `json:"line"`
+	Column    int    `json:"column"`
+	Original  string `json:"original"`
+	Converted string `json:"converted"`
+	// Dictionaries are the entries, as file:line, that changed the span
+	Dictionaries []string `json:"dictionaries"`
+}
+
+// checkFile reads a file, or stdin for "-", and returns the spans the
+// conversion would change
+func checkFile(converter *opencc.SimpleConverter, file string, from *textEncoding) ([]checkChange, error) {
+	var data []byte
+	var err error
+	name := file
+	if file == "-" {
+		name = "<stdin>"
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(file)
+	}
+	if err != nil {
+		return nil, err
+	}
+	text, _, err := decodeText(data, from)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", err, name)
+	}
+	return checkText(converter, name, text), nil
+}
+
+// checkText returns the segments of text that the conversion would change
+func checkText(converter *opencc.SimpleConverter, name, text string) []checkChange {
+	var changes []checkChange
+	for i, line := range strings.Split(text, "\n") {
+		column := 1
+		for _, e := range converter.Explain(line) {
+			if e.Source != e.Target {
+				changes = append(changes, checkChange{
+					File:         name,
+					Line:         i + 1,
+					Column:       column,
+					Original:     e.Source,
+					Converted:    e.Target,
+					Dictionaries: explanationSources(e),
+				})
+			}
+			column += utf8.RuneCountInString(e.Source)
+		}
+	}
+	return changes
+}
+
+// explanationSources lists where the entries of the steps that changed a
+// segment are defined
+func explanationSources(e opencc.Explanation) []string {
+	sources := []string{}
+	for _, step := range e.Steps {
+		if step.Entry == nil || step.Input == step.Output {
+			continue
+		}
+		source := step.Source.String()
+		if source == "" {
+			source = "(unknown source)"
+		}
+		sources = append(sources, source)
+	}
+	return sources
+}
+
+// writeChanges prints one line per change
+func writeChanges(w io.Writer, changes []checkChange) {
+	for _, c := range changes {
+		fmt.Fprintf(w, "%s:%d:%d: %s → %s", c.File, c.Line, c.Column, quoteSegment(c.Original), quoteSegment(c.Converted))
+		if len(c.Dictionaries) > 0 {
+			fmt.Fprintf(w, " (%s)", strings.Join(c.Dictionaries, ", "))
+		}
+		fmt.Fprintln(w)
+	}
+}
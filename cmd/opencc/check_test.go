@@ -0,0 +1,126 @@
+/*
+ * Open Chinese Convert
+ *
+ * Copyright 2010-2014 Carbo Kuo <byvoid@byvoid.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckText(t *testing.T) {
+	converter, err := newConverter("s2t")
+	require.NoError(t, err)
+
+	changes := checkText(converter, "a.md", "汉字\r\n  头发和字\nabc")
+	require.Len(t, changes, 2)
+	assert.Equal(t, "a.md", changes[0].File)
+	assert.Equal(t, 1, changes[0].Line)
+	assert.Equal(t, 1, changes[0].Column)
+	assert.Equal(t, "汉", changes[0].Original)
+	assert.Equal(t, "漢", changes[0].Converted)
+	assert.Equal(t, 2, changes[1].Line)
+	assert.Equal(t, 3, changes[1].Column)
+	assert.Equal(t, "头发", changes[1].Original)
+	assert.Equal(t, "頭髮", changes[1].Converted)
+	require.Len(t, changes[1].Dictionaries, 1)
+	assert.Contains(t, changes[1].Dictionaries[0], "STPhrases.txt:")
+
+	assert.Empty(t, checkText(converter, "b.md", "繁體與漢字\nabc"))
+}
+
+func TestCheckFile(t *testing.T) {
+	converter, err := newConverter("s2t")
+	require.NoError(t, err)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "gbk.txt")
+	require.NoError(t, os.WriteFile(path, []byte("\xba\xba\xd7\xd6"), 0644))
+
+	_, err = checkFile(converter, path, encodingUTF8)
+	assert.ErrorIs(t, err, errNotText)
+
+	changes, err := checkFile(converter, path, encodingGBK)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	assert.Equal(t, "汉", changes[0].Original)
+
+	var out bytes.Buffer
+	writeChanges(&out, changes)
+	assert.Equal(t, path+":1:1: 汉 → 漢 ("+changes[0].Dictionaries[0]+")\n", out.String())
+}
+
+// runCommand runs a subcommand and returns its exit status and output
+func runCommand(t *testing.T, run func(args []string) int, args ...string) (int, string) {
+	stdout, stderr := os.Stdout, os.Stderr
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	os.Stdout, os.Stderr = w, w
+	output := make(chan []byte)
+	go func() {
+		data, _ := io.ReadAll(r)
+		output <- data
+	}()
+
+	status := run(args)
+	os.Stdout, os.Stderr = stdout, stderr
+	w.Close()
+	return status, string(<-output)
+}
+
+func TestRunCheck(t *testing.T) {
+	dir := t.TempDir()
+	changed := filepath.Join(dir, "changed.txt")
+	unchanged := filepath.Join(dir, "unchanged.txt")
+	require.NoError(t, os.WriteFile(changed, []byte("abc\n偽裝\n"), 0644))
+	require.NoError(t, os.WriteFile(unchanged, []byte("abc\n"), 0644))
+
+	status, output := runCommand(t, runCheck, "-c", "tw2t", changed)
+	assert.Equal(t, 0, status)
+	assert.Contains(t, output, changed+":2:1: 偽 → 僞 (")
+
+	status, output = runCommand(t, runCheck, "-c", "tw2t", "--fail-on-change", unchanged, changed)
+	assert.Equal(t, 1, status)
+	assert.Contains(t, output, "1 span would change\n")
+	status, _ = runCommand(t, runCheck, "-c", "tw2t", "--fail-on-change", unchanged)
+	assert.Equal(t, 0, status)
+
+	status, _ = runCommand(t, runCheck, "-c", "tw2t", "--fail-on-change", changed, filepath.Join(dir, "missing.txt"))
+	assert.Equal(t, 2, status)
+	status, _ = runCommand(t, runCheck, changed)
+	assert.Equal(t, 2, status)
+
+	status, output = runCommand(t, runCheck, "-c", "tw2t", "--json", changed, unchanged)
+	assert.Equal(t, 0, status)
+	var changes []checkChange
+	require.NoError(t, json.Unmarshal([]byte(output), &changes))
+	require.Len(t, changes, 1)
+	assert.Equal(t, checkChange{File: changed, Line: 2, Column: 1, Original: "偽", Converted: "僞", Dictionaries: changes[0].Dictionaries}, changes[0])
+	require.Len(t, changes[0].Dictionaries, 1)
+	assert.Contains(t, changes[0].Dictionaries[0], "TWVariantsRev.txt:")
+
+	status, output = runCommand(t, runCheck, "-c", "tw2t", "--json", unchanged)
+	assert.Equal(t, 0, status)
+	assert.Equal(t, "[]\n", output)
+}
@@ -0,0 +1,84 @@
+/*
+ * Open Chinese Convert
+ *
+ * Copyright 2010-2014 Carbo Kuo <byvoid@byvoid.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/yanmingcao/opencc-go"
+)
+
+func detectUsage() {
+	fmt.Fprintf(os.Stderr, "Usage: opencc detect [options] [text...]\n\n")
+	fmt.Fprintf(os.Stderr, "Tells whether the text is Simplified or Traditional Chinese, mixed or\n")
+	fmt.Fprintf(os.Stderr, "neither, and which regional variants it uses. The text is read from\n")
+	fmt.Fprintf(os.Stderr, "stdin if not given.\n\n")
+	fmt.Fprintf(os.Stderr, "Options:\n")
+	fmt.Fprintf(os.Stderr, "  --json                      Print the result as JSON\n")
+}
+
+// runDetect implements the "opencc detect" subcommand
+func runDetect(args []string) int {
+	flags := flag.NewFlagSet("detect", flag.ContinueOnError)
+	flags.Usage = detectUsage
+	asJSON := flags.Bool("json", false, "Print the result as JSON")
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 1
+	}
+
+	text := strings.Join(flags.Args(), " ")
+	if flags.NArg() == 0 {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading input: %v\n", err)
+			return 1
+		}
+		text = string(data)
+	}
+
+	detection := opencc.Detect(text)
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(detection); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+	writeDetection(os.Stdout, detection)
+	return 0
+}
+
+// writeDetection prints a detection result for people
+func writeDetection(w io.Writer, d opencc.Detection) {
+	fmt.Fprintf(w, "script: %s (confidence %.2f)\n", d.Script, d.Confidence)
+	fmt.Fprintf(w, "characters: %d simplified, %d traditional, %d Chinese in total\n", d.Simplified, d.Traditional, d.Han)
+	for _, hint := range d.Hints {
+		fmt.Fprintf(w, "variant: %s (confidence %.2f, characters: %d)\n", hint.Variant, hint.Confidence, hint.Count)
+	}
+}
@@ -0,0 +1,114 @@
+/*
+ * Open Chinese Convert
+ *
+ * Copyright 2010-2014 Carbo Kuo <byvoid@byvoid.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/yanmingcao/opencc-go/pkg/dict"
+	"github.com/yanmingcao/opencc-go/pkg/embeddata"
+)
+
+// runDict implements the "opencc dict" subcommand
+func runDict(args []string) int {
+	if len(args) == 0 {
+		dictUsage()
+		return 1
+	}
+
+	switch args[0] {
+	case "compile":
+		return runDictCompile(args[1:])
+	case "-h", "--help", "help":
+		dictUsage()
+		return 0
+	default:
+		fmt.Fprintf(os.Stderr, "Error: Unknown dict command: %s\n\n", args[0])
+		dictUsage()
+		return 1
+	}
+}
+
+func dictUsage() {
+	fmt.Fprintf(os.Stderr, "Usage: opencc dict compile <input.txt> <output.bin>\n\n")
+	fmt.Fprintf(os.Stderr, "Commands:\n")
+	fmt.Fprintf(os.Stderr, "  compile    Compile a text dictionary into the native binary format\n")
+	fmt.Fprintf(os.Stderr, "\nThe input may also name an embedded dictionary (e.g., STPhrases.txt).\n")
+	fmt.Fprintf(os.Stderr, "Use the output with \"type\": \"bin\" in a configuration file.\n")
+}
+
+// runDictCompile compiles a text dictionary into the native binary format
+func runDictCompile(args []string) int {
+	flags := flag.NewFlagSet("dict compile", flag.ContinueOnError)
+	flags.Usage = dictUsage
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 1
+	}
+	if flags.NArg() != 2 {
+		dictUsage()
+		return 1
+	}
+	input, output := flags.Arg(0), flags.Arg(1)
+
+	lexicon, err := readTextLexicon(input)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Cannot read dictionary: %v\n", err)
+		return 1
+	}
+
+	lexicon.Sort()
+	var dupkey string
+	if !lexicon.IsUnique(&dupkey) {
+		fmt.Fprintf(os.Stderr, "Warning: Duplicate key in %s: %s\n", input, dupkey)
+	}
+
+	d, err := dict.NewCompiledDict(lexicon)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Cannot compile dictionary: %v\n", err)
+		return 1
+	}
+	if err := d.SerializeToFile(output); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Cannot write dictionary: %v\n", err)
+		return 1
+	}
+
+	fmt.Fprintf(os.Stderr, "Compiled %d entries from %s to %s\n", d.Len(), input, output)
+	return 0
+}
+
+// readTextLexicon parses a text dictionary from disk, falling back to the
+// embedded dictionary of the same name
+func readTextLexicon(name string) (*dict.Lexicon, error) {
+	if _, err := os.Stat(name); err == nil || filepath.Base(name) != name {
+		return dict.ParseLexiconFromFile(name)
+	}
+	content, err := embeddata.GetDict(name)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, os.ErrNotExist)
+	}
+	return dict.ParseLexiconFromReader(bufio.NewReader(bytes.NewReader(content)))
+}
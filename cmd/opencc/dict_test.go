@@ -0,0 +1,64 @@
+/*
+ * Open Chinese Convert
+ *
+ * Copyright 2010-2014 Carbo Kuo <byvoid@byvoid.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yanmingcao/opencc-go"
+)
+
+func TestRunDictCompile(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "Custom.txt")
+	compiled := filepath.Join(dir, "Custom.bin")
+	require.NoError(t, os.WriteFile(source, []byte("汉字\t漢字\n头发\t頭髮 頭發\n汉\t漢\n"), 0644))
+
+	status, output := runCommand(t, runDict, "compile", source, compiled)
+	require.Equal(t, 0, status, output)
+	assert.Contains(t, output, "Compiled 3 entries")
+
+	configFile := filepath.Join(dir, "custom.json")
+	configData := fmt.Sprintf(`{
+  "name": "Custom",
+  "segmentation": {"type": "mmseg", "dict": {"type": "bin", "file": %q}},
+  "conversion_chain": [{"dict": {"type": "bin", "file": %q}}]
+}`, compiled, compiled)
+	require.NoError(t, os.WriteFile(configFile, []byte(configData), 0644))
+
+	converter, err := opencc.NewSimpleConverter(configFile)
+	require.NoError(t, err)
+	defer converter.Close()
+	assert.Equal(t, "漢字和頭髮", converter.Convert("汉字和头发"))
+
+	// Recompiling replaces the file rather than rewriting the mapped one
+	require.NoError(t, os.WriteFile(source, []byte("汉\t漢\n"), 0644))
+	status, output = runCommand(t, runDict, "compile", source, compiled)
+	require.Equal(t, 0, status, output)
+	assert.Equal(t, "漢字和頭髮", converter.Convert("汉字和头发"))
+
+	status, _ = runCommand(t, runDict, "compile", filepath.Join(dir, "missing.txt"), compiled)
+	assert.Equal(t, 1, status)
+}
@@ -0,0 +1,418 @@
+/*
+ * Open Chinese Convert
+ *
+ * Copyright 2010-2014 Carbo Kuo <byvoid@byvoid.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// detectSampleSize is how much input is examined to detect its encoding
+const detectSampleSize = 64 * 1024
+
+// gb18030BOM is the byte order mark of GB18030, recognized on input only
+const gb18030BOM = "\x84\x31\x95\x33"
+
+// textEncoding is a character encoding of input or output text
+type textEncoding struct {
+	name string
+	// encoding is nil for UTF-8, which is converted without transcoding
+	encoding encoding.Encoding
+	// bom is the byte order mark of Unicode encodings
+	bom string
+	// wide is set for UTF-16, which does not encode ASCII as single bytes
+	wide bool
+	// valid, if set, restricts the characters that may be written to those
+	// whose encoded form it accepts
+	valid func(encoded []byte) bool
+}
+
+var (
+	encodingUTF8    = &textEncoding{name: "utf-8", bom: "\xef\xbb\xbf"}
+	encodingUTF16LE = &textEncoding{name: "utf-16le", encoding: unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM), bom: "\xff\xfe", wide: true}
+	encodingUTF16BE = &textEncoding{name: "utf-16be", encoding: unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM), bom: "\xfe\xff", wide: true}
+	encodingGB18030 = &textEncoding{name: "gb18030", encoding: simplifiedchinese.GB18030}
+	encodingGBK     = &textEncoding{name: "gbk", encoding: simplifiedchinese.GBK}
+	// GB2312 is the EUC-CN subset of GBK
+	encodingGB2312 = &textEncoding{name: "gb2312", encoding: simplifiedchinese.GBK, valid: func(b []byte) bool {
+		return len(b) == 1 && b[0] < utf8.RuneSelf || len(b) == 2 && 0xa1 <= b[0] && b[0] <= 0xf7 && 0xa1 <= b[1] && b[1] <= 0xfe
+	}}
+	// The Big5 of x/text is the WHATWG one, which includes the HKSCS
+	// extensions
+	encodingBig5HKSCS = &textEncoding{name: "big5-hkscs", encoding: traditionalchinese.Big5}
+	// Plain Big5 leaves out the HKSCS rows and the user-defined area
+	encodingBig5 = &textEncoding{name: "big5", encoding: traditionalchinese.Big5, valid: func(b []byte) bool {
+		if len(b) == 1 {
+			return b[0] < utf8.RuneSelf
+		}
+		code := uint16(b[0])<<8 | uint16(b[1])
+		return 0xa1 <= b[0] && b[0] <= 0xf9 && !(0xc6a1 <= code && code <= 0xc8fe)
+	}}
+)
+
+// textEncodings maps encoding names and aliases to encodings
+var textEncodings = map[string]*textEncoding{
+	"utf-8":      encodingUTF8,
+	"utf8":       encodingUTF8,
+	"utf-16le":   encodingUTF16LE,
+	"utf-16be":   encodingUTF16BE,
+	"gb18030":    encodingGB18030,
+	"gbk":        encodingGBK,
+	"cp936":      encodingGBK,
+	"gb2312":     encodingGB2312,
+	"euc-cn":     encodingGB2312,
+	"big5":       encodingBig5,
+	"cp950":      encodingBig5,
+	"big5-hkscs": encodingBig5HKSCS,
+	"big5hkscs":  encodingBig5HKSCS,
+}
+
+// encodingNames lists the encodings accepted by --from-encoding and
+// --to-encoding
+const encodingNames = "utf-8, utf-16le, utf-16be, gb18030, gbk, gb2312, big5, big5-hkscs"
+
+// lookupEncoding returns the encoding with the given name or alias. For
+// input, "auto" selects detection and is returned as nil.
+func lookupEncoding(name string, allowAuto bool) (*textEncoding, error) {
+	name = strings.ToLower(name)
+	if name == "" {
+		return encodingUTF8, nil
+	}
+	if allowAuto && name == "auto" {
+		return nil, nil
+	}
+	if enc, ok := textEncodings[name]; ok {
+		return enc, nil
+	}
+	return nil, fmt.Errorf("unknown encoding: %s (supported: %s)", name, encodingNames)
+}
+
+// isUTF8 reports whether text in this encoding needs no transcoding
+func (e *textEncoding) isUTF8() bool {
+	return e.encoding == nil
+}
+
+// decoder returns a reader of r decoded to UTF-8
+func (e *textEncoding) decoder(r io.Reader) io.Reader {
+	if e.isUTF8() {
+		return r
+	}
+	return e.encoding.NewDecoder().Reader(r)
+}
+
+// encodeRune returns the encoded form of r, or false if the encoding
+// cannot represent it
+func (e *textEncoding) encodeRune(r rune) ([]byte, bool) {
+	if e.isUTF8() {
+		return utf8.AppendRune(nil, r), true
+	}
+	encoded, err := e.encoding.NewEncoder().Bytes(utf8.AppendRune(nil, r))
+	if err != nil || e.valid != nil && !e.valid(encoded) {
+		return nil, false
+	}
+	return encoded, true
+}
+
+// splitEncodingBOM splits the byte order mark of enc, or with enc nil of
+// any known encoding, off data. It returns the encoding the mark belongs to.
+func splitEncodingBOM(data []byte, enc *textEncoding) (*textEncoding, []byte, bool) {
+	for _, candidate := range []*textEncoding{encodingUTF8, encodingUTF16LE, encodingUTF16BE} {
+		if (enc == nil || enc == candidate) && bytes.HasPrefix(data, []byte(candidate.bom)) {
+			return candidate, data[len(candidate.bom):], true
+		}
+	}
+	if (enc == nil || enc == encodingGB18030) && bytes.HasPrefix(data, []byte(gb18030BOM)) {
+		return encodingGB18030, data[len(gb18030BOM):], true
+	}
+	return enc, data, false
+}
+
+// commonHanzi are frequent characters in simplified and traditional forms,
+// used to tell GBK from Big5
+const commonHanzi = "的一是不了人我在有他这中大来上国个到说们为子和你地出道也时年得就那要下以生会自着去之过家学对可她里后小么心多天而能好都然没日于起还发成事只作当想看文无开手十用主行方又如前所本见经头面公同三已老从动两长知民样现分将外但身些与高意进把法此实回二理美点月明其种声全工己话儿者向情部正名定女问力机给等几很业最间新什打便位因重被走电四第门相次东政海口使教西再平真听世气信北少关并内加化由却代军产入先山五太水万市眼体别处总才场师书比住员九笑性通目华报立马命张活难神数件安表原车白应路期叫死常提感金何更反合放做系计或司利受光王果亲界及今京务制解各任至清物台象记边共风战干接它许八特觉望直服毛林题建南度统色字请交爱让认算论百吃义科怎元社术结六功指思非流每青管夫连远资队跟带花快条院变联言权往展该领传近留红治决周保达办运武半候七必城父强步完革深区即求品士转量空甚众技轻程告江语英基派满式李息写呢识极令黄德收脸钱党倒未持取设始版双历越史商千片容研像找友孩站广改议形委早房音火际则首单据导影失拿网香似斯专石若兵弟谁校读志飞观争究包组造落视济喜离虽坏兴" +
+	"這來國個說們爲為時會著過學對裏裡後麼頭見經動兩長樣現將與實點種聲話兒問機給幾業間電門東聽氣關並內卻軍產萬體別處總場師書員華報馬張難數車應親務邊風戰幹許覺題統請愛讓認論喫義術結連遠資隊帶條變聯權該領傳紅決達辦強區轉眾衆輕語滿寫識極黃臉錢黨設雙歷單據導網專誰讀飛觀爭組視濟離雖壞興"
+
+var commonHanziSet = func() map[rune]bool {
+	set := make(map[rune]bool)
+	for _, r := range commonHanzi {
+		set[r] = true
+	}
+	return set
+}()
+
+// detectEncoding guesses the encoding of sample, the start of the input:
+// UTF-8 if it is valid UTF-8, otherwise whichever of GB18030 and
+// Big5-HKSCS decodes it to more common Chinese characters and fewer
+// invalid sequences
+func detectEncoding(sample []byte, atEOF bool) *textEncoding {
+	text := sample
+	if !atEOF {
+		// The sample may end in the middle of a character
+		for i := len(text) - 1; i >= 0 && i >= len(text)-utf8.UTFMax; i-- {
+			if utf8.RuneStart(text[i]) {
+				if !utf8.FullRune(text[i:]) {
+					text = text[:i]
+				}
+				break
+			}
+		}
+	}
+	if utf8.Valid(text) {
+		return encodingUTF8
+	}
+
+	best, bestScore := encodingGB18030, 0
+	for i, enc := range []*textEncoding{encodingGB18030, encodingBig5HKSCS} {
+		decoded, _ := enc.encoding.NewDecoder().Bytes(sample)
+		score := 0
+		for _, r := range string(decoded) {
+			switch {
+			case r == utf8.RuneError:
+				score -= 10
+			case commonHanziSet[r]:
+				score++
+			}
+		}
+		if i == 0 || score > bestScore {
+			best, bestScore = enc, score
+		}
+	}
+	return best
+}
+
+// encodingOptions selects the encodings of the input and the output
+type encodingOptions struct {
+	// from is nil to detect the encoding of the input
+	from *textEncoding
+	to   *textEncoding
+}
+
+// parseEncodings looks up the encodings named by --from-encoding and
+// --to-encoding
+func parseEncodings(from, to string) (encodingOptions, error) {
+	var encodings encodingOptions
+	var err error
+	if encodings.from, err = lookupEncoding(from, true); err != nil {
+		return encodings, err
+	}
+	encodings.to, err = lookupEncoding(to, false)
+	return encodings, err
+}
+
+// openInput skips the byte order mark at the start of reader, detects the
+// encoding of the input if from is nil and returns a reader of the decoded
+// text. It reports whether the input had a byte order mark.
+func openInput(reader *bufio.Reader, from *textEncoding) (io.Reader, bool, error) {
+	n := len(gb18030BOM)
+	if from == nil {
+		n = detectSampleSize
+	}
+	sample, err := reader.Peek(n)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, false, err
+	}
+	enc, rest, hadBOM := splitEncodingBOM(sample, from)
+	reader.Discard(len(sample) - len(rest))
+	if enc == nil {
+		enc = detectEncoding(rest, err == io.EOF)
+	}
+	return enc.decoder(reader), hadBOM, nil
+}
+
+// decodeText decodes the content of a file to UTF-8, detecting its
+// encoding if from is nil. It returns errNotText for binary files and for
+// files that are not valid in their encoding. It reports whether the file
+// had a byte order mark.
+func decodeText(data []byte, from *textEncoding) (string, bool, error) {
+	enc, rest, hadBOM := splitEncodingBOM(data, from)
+	unicodeBOM := hadBOM && enc != encodingGB18030
+	// UTF-16 text is full of NUL bytes
+	if !unicodeBOM && enc != encodingUTF16LE && enc != encodingUTF16BE &&
+		bytes.IndexByte(rest[:min(len(rest), binarySniffLength)], 0) >= 0 {
+		return "", false, errNotText
+	}
+	if enc == nil {
+		enc = detectEncoding(rest[:min(len(rest), detectSampleSize)], len(rest) <= detectSampleSize)
+	}
+	if enc.isUTF8() {
+		if !utf8.Valid(rest) {
+			return "", false, errNotText
+		}
+		return string(rest), hadBOM, nil
+	}
+	decoded, err := enc.encoding.NewDecoder().Bytes(rest)
+	if err != nil {
+		return "", false, errNotText
+	}
+	return string(decoded), hadBOM, nil
+}
+
+// encodeText encodes UTF-8 text to enc, starting with a byte order mark if
+// withBOM is set and enc has one. It returns an *unmappableError if some
+// characters cannot be represented.
+func encodeText(text string, enc *textEncoding, withBOM bool) ([]byte, error) {
+	var buf bytes.Buffer
+	if withBOM {
+		buf.WriteString(enc.bom)
+	}
+	if enc.isUTF8() {
+		buf.WriteString(text)
+		return buf.Bytes(), nil
+	}
+	w := newEncodeWriter(&buf, enc)
+	w.Write([]byte(text))
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// maxReportedUnmappable limits how many unmappable characters are listed
+const maxReportedUnmappable = 20
+
+// unmappableChar is a character that the output encoding cannot represent
+type unmappableChar struct {
+	line   int
+	column int
+	char   rune
+}
+
+// unmappableError reports the characters that could not be encoded
+type unmappableError struct {
+	encoding string
+	// chars holds the first maxReportedUnmappable characters
+	chars []unmappableChar
+	count int
+}
+
+func (e *unmappableError) Error() string {
+	var b strings.Builder
+	if e.count == 1 {
+		fmt.Fprintf(&b, "1 character cannot be represented in %s", e.encoding)
+	} else {
+		fmt.Fprintf(&b, "%d characters cannot be represented in %s", e.count, e.encoding)
+	}
+	for _, c := range e.chars {
+		fmt.Fprintf(&b, "\n  line %d, column %d: %q (U+%04X)", c.line, c.column, c.char, c.char)
+	}
+	if e.count > len(e.chars) {
+		fmt.Fprintf(&b, "\n  and %d more", e.count-len(e.chars))
+	}
+	return b.String()
+}
+
+// encodeWriter encodes UTF-8 text written to it. Characters the encoding
+// cannot represent are written as '?' and recorded with their position.
+type encodeWriter struct {
+	w   io.Writer
+	enc *textEncoding
+	// cache holds the encoded form of every character seen, nil if it
+	// cannot be encoded
+	cache map[rune][]byte
+	// pending holds an incomplete character at the end of the last write
+	pending []byte
+	// substitute is the encoded form of '?'
+	substitute   []byte
+	line, column int
+	err          unmappableError
+	buf          []byte
+}
+
+// newEncodeWriter creates an encodeWriter writing to w
+func newEncodeWriter(w io.Writer, enc *textEncoding) *encodeWriter {
+	substitute, _ := enc.encodeRune('?')
+	return &encodeWriter{
+		w:          w,
+		enc:        enc,
+		cache:      make(map[rune][]byte),
+		substitute: substitute,
+		line:       1,
+		err:        unmappableError{encoding: enc.name},
+	}
+}
+
+func (e *encodeWriter) Write(p []byte) (int, error) {
+	data := append(e.pending, p...)
+	e.buf = e.buf[:0]
+	i := 0
+	for i < len(data) && utf8.FullRune(data[i:]) {
+		r, size := utf8.DecodeRune(data[i:])
+		i += size
+		e.encode(r, size)
+	}
+	e.pending = append(e.pending[:0], data[i:]...)
+	if _, err := e.w.Write(e.buf); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// encode appends the encoded form of r, decoded from size bytes, to buf
+func (e *encodeWriter) encode(r rune, size int) {
+	e.column++
+	if r == '\n' {
+		e.line++
+		e.column = 0
+	}
+	if r < utf8.RuneSelf && !e.enc.wide {
+		e.buf = append(e.buf, byte(r))
+		return
+	}
+
+	encoded, ok := e.cache[r]
+	if !ok && !(r == utf8.RuneError && size == 1) {
+		encoded, _ = e.enc.encodeRune(r)
+		e.cache[r] = encoded
+	}
+	if encoded == nil {
+		e.err.count++
+		if len(e.err.chars) < maxReportedUnmappable {
+			e.err.chars = append(e.err.chars, unmappableChar{line: e.line, column: e.column, char: r})
+		}
+		e.buf = append(e.buf, e.substitute...)
+		return
+	}
+	e.buf = append(e.buf, encoded...)
+}
+
+// Close encodes an incomplete trailing character and returns an
+// *unmappableError if any character could not be encoded
+func (e *encodeWriter) Close() error {
+	e.buf = e.buf[:0]
+	for range e.pending {
+		e.encode(utf8.RuneError, 1)
+	}
+	e.pending = nil
+	if _, err := e.w.Write(e.buf); err != nil {
+		return err
+	}
+	if e.err.count > 0 {
+		return &e.err
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+/*
+ * Open Chinese Convert
+ *
+ * Copyright 2010-2014 Carbo Kuo <byvoid@byvoid.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var utf8Encodings = encodingOptions{from: encodingUTF8, to: encodingUTF8}
+
+// mustEncode encodes text to enc, failing the test on unmappable characters
+func mustEncode(t *testing.T, text string, enc *textEncoding) string {
+	encoded, err := encodeText(text, enc, false)
+	require.NoError(t, err)
+	return string(encoded)
+}
+
+func TestLookupEncoding(t *testing.T) {
+	for name, expected := range map[string]*textEncoding{
+		"":           encodingUTF8,
+		"UTF8":       encodingUTF8,
+		"cp936":      encodingGBK,
+		"EUC-CN":     encodingGB2312,
+		"Big5-HKSCS": encodingBig5HKSCS,
+	} {
+		enc, err := lookupEncoding(name, false)
+		require.NoError(t, err)
+		assert.Same(t, expected, enc, name)
+	}
+
+	enc, err := lookupEncoding("auto", true)
+	require.NoError(t, err)
+	assert.Nil(t, enc)
+	_, err = lookupEncoding("auto", false)
+	assert.Error(t, err)
+	_, err = lookupEncoding("latin1", true)
+	assert.ErrorContains(t, err, "unknown encoding: latin1")
+}
+
+func TestEncodeRune(t *testing.T) {
+	tests := []struct {
+		enc  *textEncoding
+		char rune
+		ok   bool
+	}{
+		{encodingGBK, '漢', true},
+		{encodingGB2312, '汉', true},
+		{encodingGB2312, '漢', false},
+		{encodingBig5, '漢', true},
+		{encodingBig5, '汉', false},
+		{encodingBig5HKSCS, '㗎', true},
+		{encodingBig5, '㗎', false},
+		{encodingGB18030, '😀', true},
+		{encodingGBK, '😀', false},
+	}
+	for _, tt := range tests {
+		_, ok := tt.enc.encodeRune(tt.char)
+		assert.Equal(t, tt.ok, ok, "%s %c", tt.enc.name, tt.char)
+	}
+}
+
+func TestDetectEncoding(t *testing.T) {
+	simplified := "这是一个简体中文的例子，我们来看看它是不是能被正确地识别出来。"
+	traditional := "這是一個繁體中文的例子，我們來看看它是不是能被正確地識別出來。"
+
+	assert.Same(t, encodingUTF8, detectEncoding([]byte(simplified), true))
+	assert.Same(t, encodingGB18030, detectEncoding([]byte(mustEncode(t, simplified, encodingGBK)), true))
+	assert.Same(t, encodingGB18030, detectEncoding([]byte(mustEncode(t, traditional, encodingGBK)), true))
+	assert.Same(t, encodingBig5HKSCS, detectEncoding([]byte(mustEncode(t, traditional, encodingBig5)), true))
+	// A sample cut in the middle of a UTF-8 character is still UTF-8
+	cut := []byte(simplified)[:len(simplified)-1]
+	assert.Same(t, encodingUTF8, detectEncoding(cut, false))
+}
+
+func TestDecodeText(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		from   *textEncoding
+		text   string
+		hadBOM bool
+		err    error
+	}{
+		{"utf-8", "汉字", encodingUTF8, "汉字", false, nil},
+		{"utf-8 bom", "\uFEFF汉字", nil, "汉字", true, nil},
+		{"utf-16le bom", "\xff\xfe\x49\x6c", nil, "汉", true, nil},
+		{"utf-16be", "\x6c\x49", encodingUTF16BE, "汉", false, nil},
+		{"gbk", "\xba\xba\xd7\xd6", encodingGBK, "汉字", false, nil},
+		{"invalid utf-8", "\xba\xba\xd7\xd6", encodingUTF8, "", false, errNotText},
+		{"binary", "\x89PNG\x00", nil, "", false, errNotText},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, hadBOM, err := decodeText([]byte(tt.data), tt.from)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.text, text)
+			assert.Equal(t, tt.hadBOM, hadBOM)
+		})
+	}
+}
+
+func TestEncodeWriter(t *testing.T) {
+	var out bytes.Buffer
+	w := newEncodeWriter(&out, encodingGB2312)
+	text := []byte("汉字\n漢字 and 體")
+	// Write a byte at a time to split characters between writes
+	for i := range text {
+		_, err := w.Write(text[i : i+1])
+		require.NoError(t, err)
+	}
+
+	err := w.Close()
+	var unmappable *unmappableError
+	require.True(t, errors.As(err, &unmappable))
+	assert.Equal(t, 2, unmappable.count)
+	assert.Equal(t, []unmappableChar{{line: 2, column: 1, char: '漢'}, {line: 2, column: 8, char: '體'}}, unmappable.chars)
+	assert.Contains(t, err.Error(), "line 2, column 1: '漢' (U+6F22)")
+	assert.Equal(t, mustEncode(t, "汉字\n?字 and ?", encodingGB2312), out.String())
+}
+
+func TestEncodeWriterUTF16(t *testing.T) {
+	var out bytes.Buffer
+	w := newEncodeWriter(&out, encodingUTF16LE)
+	_, err := w.Write([]byte("a汉\n"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	assert.Equal(t, "a\x00\x49\x6c\n\x00", out.String())
+}
+
+func TestConvertInputEncodings(t *testing.T) {
+	converter, err := newConverter("s2t")
+	require.NoError(t, err)
+	input := "头发和干部，简体汉字。\r\n"
+
+	var out bytes.Buffer
+	encodings := encodingOptions{to: encodingBig5}
+	require.NoError(t, convertInput(converter, strings.NewReader(mustEncode(t, input, encodingGBK)), &out, false, encodings))
+	assert.Equal(t, mustEncode(t, converter.Convert(input), encodingBig5), out.String())
+
+	// A byte order mark is carried over to the output encoding
+	out.Reset()
+	encodings = encodingOptions{from: encodingUTF8, to: encodingUTF16BE}
+	require.NoError(t, convertInput(converter, strings.NewReader("\uFEFF汉"), &out, false, encodings))
+	assert.Equal(t, "\xfe\xff\x6f\x22", out.String())
+
+	// Traditional characters have no place in GB2312
+	out.Reset()
+	encodings = encodingOptions{from: encodingUTF8, to: encodingGB2312}
+	err = convertInput(converter, strings.NewReader("汉字\n简体"), &out, false, encodings)
+	var unmappable *unmappableError
+	require.True(t, errors.As(err, &unmappable))
+	assert.Equal(t, 3, unmappable.count)
+	assert.Equal(t, mustEncode(t, "?字\n??", encodingGB2312), out.String())
+}
+
+func TestConvertTreeEncodings(t *testing.T) {
+	converter, err := newConverter("s2t")
+	require.NoError(t, err)
+	source := t.TempDir()
+	writeTree(t, source, map[string]string{
+		"gbk.txt":   mustEncode(t, "简体汉字", encodingGBK),
+		"utf8.txt":  "简体汉字",
+		"emoji.txt": "汉😀",
+	})
+
+	output := filepath.Join(source, "out")
+	result := convertTree(converter, batchOptions{
+		source:    source,
+		output:    output,
+		encodings: encodingOptions{to: encodingBig5},
+	})
+	assert.Equal(t, 2, result.converted)
+	require.Len(t, result.errs, 1)
+	assert.ErrorContains(t, result.errs[0], "emoji.txt")
+	assert.Equal(t, mustEncode(t, "簡體漢字", encodingBig5), readFile(t, filepath.Join(output, "gbk.txt")))
+	assert.Equal(t, mustEncode(t, "簡體漢字", encodingBig5), readFile(t, filepath.Join(output, "utf8.txt")))
+	assert.NoFileExists(t, filepath.Join(output, "emoji.txt"))
+}
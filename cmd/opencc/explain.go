@@ -0,0 +1,122 @@
+/*
+ * Open Chinese Convert
+ *
+ * Copyright 2010-2014 Carbo Kuo <byvoid@byvoid.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/yanmingcao/opencc-go"
+)
+
+func explainUsage() {
+	fmt.Fprintf(os.Stderr, "Usage: opencc explain -c <preset|config-file> [options] [text...]\n\n")
+	fmt.Fprintf(os.Stderr, "Shows, for every segment, the dictionary entry matched by each step of\n")
+	fmt.Fprintf(os.Stderr, "the conversion chain. The text is read from stdin if not given.\n\n")
+	fmt.Fprintf(os.Stderr, "Options:\n")
+	fmt.Fprintf(os.Stderr, "  -c, --config <preset|file>  Conversion preset (e.g., s2twp) or config file path\n")
+	fmt.Fprintf(os.Stderr, "  -a, --all                   Also show segments that no step matched\n")
+}
+
+// runExplain implements the "opencc explain" subcommand
+func runExplain(args []string) int {
+	flags := flag.NewFlagSet("explain", flag.ContinueOnError)
+	flags.Usage = explainUsage
+	var configFile string
+	var showAll bool
+	flags.StringVar(&configFile, "c", "", "Conversion preset or config file")
+	flags.StringVar(&configFile, "config", "", "Conversion preset or config file")
+	flags.BoolVar(&showAll, "a", false, "Show all segments")
+	flags.BoolVar(&showAll, "all", false, "Show all segments")
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 1
+	}
+	if configFile == "" {
+		fmt.Fprintf(os.Stderr, "Error: Conversion preset is required (-c or --config)\n\n")
+		explainUsage()
+		return 1
+	}
+
+	converter, err := newConverter(configFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	text := strings.Join(flags.Args(), " ")
+	if flags.NArg() == 0 {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading input: %v\n", err)
+			return 1
+		}
+		text = string(data)
+	}
+
+	writer := bufio.NewWriter(os.Stdout)
+	defer writer.Flush()
+	writeExplanations(writer, converter.Explain(text), showAll)
+	return 0
+}
+
+// writeExplanations prints explanations, one block per segment
+func writeExplanations(w io.Writer, explanations []opencc.Explanation, showAll bool) {
+	for _, e := range explanations {
+		matched := false
+		for _, step := range e.Steps {
+			matched = matched || step.Entry != nil
+		}
+		if !matched && !showAll {
+			continue
+		}
+
+		fmt.Fprintf(w, "%s → %s\n", quoteSegment(e.Source), quoteSegment(e.Target))
+		for i, step := range e.Steps {
+			if step.Entry == nil {
+				fmt.Fprintf(w, "  [%d] %s (no match)\n", i+1, quoteSegment(step.Input))
+				continue
+			}
+			source := step.Source.String()
+			if source == "" {
+				source = "(unknown source)"
+			}
+			fmt.Fprintf(w, "  [%d] %s → %s  %s  candidates: %s\n", i+1,
+				quoteSegment(step.Input), quoteSegment(step.Output), source,
+				strings.Join(step.Entry.Values(), " "))
+		}
+	}
+}
+
+// quoteSegment quotes segments that would not print legibly
+func quoteSegment(s string) string {
+	for _, r := range s {
+		if !strconv.IsPrint(r) {
+			return strconv.Quote(s)
+		}
+	}
+	return s
+}
@@ -0,0 +1,104 @@
+/*
+ * Open Chinese Convert
+ *
+ * Copyright 2010-2014 Carbo Kuo <byvoid@byvoid.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/yanmingcao/opencc-go"
+)
+
+// splitLineEnding splits the "\n" or "\r\n" terminator off a line
+func splitLineEnding(line string) (content, ending string) {
+	if strings.HasSuffix(line, "\r\n") {
+		return line[:len(line)-2], "\r\n"
+	}
+	if strings.HasSuffix(line, "\n") {
+		return line[:len(line)-1], "\n"
+	}
+	return line, ""
+}
+
+// convertInput converts input to output. The input is streamed in chunks,
+// so lines of any length convert in bounded memory. Line terminators, a
+// leading byte order mark and a missing final newline are written back
+// exactly as they were read. With candidates set, a JSON record of the
+// candidates of every line is written instead.
+func convertInput(converter *opencc.SimpleConverter, input io.Reader, output io.Writer, candidates bool, encodings encodingOptions) error {
+	reader := bufio.NewReaderSize(input, detectSampleSize)
+	// The byte order mark is written back rather than converted
+	src, hadBOM, err := openInput(reader, encodings.from)
+	if err != nil {
+		return err
+	}
+	if candidates {
+		return writeCandidates(converter, src, output)
+	}
+
+	writer := bufio.NewWriter(output)
+	if hadBOM {
+		writer.WriteString(encodings.to.bom)
+	}
+	var dst io.Writer = writer
+	var encoder *encodeWriter
+	if !encodings.to.isUTF8() {
+		encoder = newEncodeWriter(writer, encodings.to)
+		dst = encoder
+	}
+	err = converter.ConvertStream(context.Background(), src, dst)
+	if err == nil && encoder != nil {
+		err = encoder.Close()
+	}
+	if flushErr := writer.Flush(); err == nil {
+		err = flushErr
+	}
+	return err
+}
+
+// writeCandidates writes a JSON record of the candidates of every line
+func writeCandidates(converter *opencc.SimpleConverter, input io.Reader, output io.Writer) error {
+	reader := bufio.NewReader(input)
+	writer := bufio.NewWriter(output)
+	encoder := json.NewEncoder(writer)
+	encoder.SetEscapeHTML(false)
+
+	lineNum := 0
+	for {
+		line, err := reader.ReadString('\n')
+		if len(line) > 0 {
+			lineNum++
+			content, _ := splitLineEnding(line)
+			if err := encoder.Encode(candidatesLine{Line: lineNum, Segments: converter.ConvertCandidates(content)}); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			writer.Flush()
+			return err
+		}
+	}
+	return writer.Flush()
+}
@@ -0,0 +1,102 @@
+/*
+ * Open Chinese Convert
+ *
+ * Copyright 2010-2014 Carbo Kuo <byvoid@byvoid.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertInput(t *testing.T) {
+	converter, err := newConverter("s2t")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"empty", "", ""},
+		{"lf", "简体\n汉字\n", "簡體\n漢字\n"},
+		{"crlf", "简体\r\n汉字\r\n", "簡體\r\n漢字\r\n"},
+		{"mixed", "简体\r\n汉字\n\r\n", "簡體\r\n漢字\n\r\n"},
+		{"no final newline", "简体\n汉字", "簡體\n漢字"},
+		{"lone cr", "简体\r汉字", "簡體\r漢字"},
+		{"bom", "\uFEFF简体\r\n", "\uFEFF簡體\r\n"},
+		{"bom only", "\uFEFF", "\uFEFF"},
+		{"bom not at start", "简体\n\uFEFF汉字", "簡體\n\uFEFF漢字"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			require.NoError(t, convertInput(converter, strings.NewReader(tt.input), &out, false, utf8Encodings))
+			assert.Equal(t, tt.expected, out.String())
+		})
+	}
+}
+
+func TestConvertInputLongLines(t *testing.T) {
+	converter, err := newConverter("s2t")
+	require.NoError(t, err)
+
+	tests := map[string]string{
+		// A phrase straddling the first chunk boundary of the stream
+		"boundary": strings.Repeat("a", 64*1024-2) + "简体字与汉字",
+		// Over a MiB without a single line break
+		"no newlines": strings.Repeat("简体字与汉字，头发和干部。", 1<<15),
+		"long lines":  strings.Repeat(strings.Repeat("汉字", 40000)+"\r\n", 3),
+	}
+	for name, input := range tests {
+		t.Run(name, func(t *testing.T) {
+			var out bytes.Buffer
+			require.NoError(t, convertInput(converter, strings.NewReader(input), &out, false, utf8Encodings))
+			assert.True(t, converter.Convert(input) == out.String())
+		})
+	}
+}
+
+func TestConvertInputCandidates(t *testing.T) {
+	converter, err := newConverter("s2t")
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	require.NoError(t, convertInput(converter, strings.NewReader("\uFEFF汉\r\n"), &out, true, utf8Encodings))
+	assert.Equal(t, `{"line":1,"segments":[{"source":"汉","target":"漢","candidates":["漢"],"steps":[["漢"]]}]}`+"\n", out.String())
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func TestConvertInputCandidatesWriteError(t *testing.T) {
+	converter, err := newConverter("s2t")
+	require.NoError(t, err)
+
+	input := strings.NewReader(strings.Repeat("汉字\n", 100000))
+	err = writeCandidates(converter, input, failingWriter{})
+	require.EqualError(t, err, "write failed")
+	assert.NotZero(t, input.Len())
+}
@@ -19,16 +19,19 @@
 package main
 
 import (
-	"bufio"
 	"flag"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
 	"strings"
 
 	"github.com/yanmingcao/opencc-go"
 	"github.com/yanmingcao/opencc-go/pkg/embeddata"
+	"github.com/yanmingcao/opencc-go/pkg/segmentation"
 )
 
 const (
@@ -51,9 +54,43 @@ var configMappings = map[string]string{
 	"t2jp":  "t2jp",
 	"tw2t":  "tw2t",
 	"t2tw":  "t2tw",
+	// Automatic presets detect the script of every paragraph
+	"auto2s":  "auto2s",
+	"auto2t":  "auto2t",
+	"auto2tw": "auto2tw",
+	"auto2hk": "auto2hk",
+}
+
+// isAutoPreset reports whether name is an automatic preset
+func isAutoPreset(name string) bool {
+	return configMappings[name] != "" && strings.HasPrefix(name, "auto2")
+}
+
+// presetNames returns the embedded and automatic presets, sorted
+func presetNames() []string {
+	names := append(embeddata.ListConfigs(), opencc.AutoPresets()...)
+	sort.Strings(names)
+	return names
+}
+
+// subcommands maps subcommand names to their entry points
+var subcommands = map[string]func(args []string) int{
+	"check":   runCheck,
+	"detect":  runDetect,
+	"dict":    runDict,
+	"explain": runExplain,
+	"rename":  runRename,
+	"serve":   runServe,
 }
 
 func main() {
+	// Dispatch subcommands before parsing conversion flags
+	if len(os.Args) > 1 {
+		if run, ok := subcommands[os.Args[1]]; ok {
+			os.Exit(run(os.Args[2:]))
+		}
+	}
+
 	var (
 		configFile  = flag.String("c", "", "Conversion preset (e.g., s2t, t2s, s2tw)")
 		configLong  = flag.String("config", "", "Conversion preset or config file")
@@ -66,11 +103,56 @@ func main() {
 		showHelp    = flag.Bool("h", false, "Show help")
 		helpLong    = flag.Bool("help", false, "Show help")
 		listConfigs = flag.Bool("list", false, "List all available conversion presets")
+		candidates  = flag.Bool("candidates", false, "Output all candidates of every segment as JSON lines")
+		protect     = flag.Bool("protect", false, "Leave URLs, emails, code and HTML markup unconverted")
+		recursive   = flag.String("r", "", "Convert the directory tree rooted here")
+		recurseLong = flag.String("recursive", "", "Convert the directory tree rooted here")
+		inPlace     = flag.Bool("in-place", false, "With -r, replace the files instead of writing to -o")
+		backup      = flag.String("backup", "", "With --in-place, keep originals with this suffix (e.g., .bak)")
+		jobs        = flag.Int("j", runtime.NumCPU(), "Number of files converted in parallel")
+		jobsLong    = flag.Int("jobs", 0, "Number of files converted in parallel")
+		fromEnc     = flag.String("from-encoding", "utf-8", "Encoding of the input, or auto to detect it")
+		toEnc       = flag.String("to-encoding", "utf-8", "Encoding of the output")
 	)
+	var includes, excludes, extensions []string
+	flag.Func("include", "With -r, only convert files matching the glob (repeatable)", func(value string) error {
+		includes = append(includes, value)
+		return nil
+	})
+	flag.Func("exclude", "With -r, skip files and directories matching the glob (repeatable)", func(value string) error {
+		excludes = append(excludes, value)
+		return nil
+	})
+	flag.Func("ext", "With -r, only convert files with these comma-separated extensions", func(value string) error {
+		extensions = append(extensions, strings.Split(value, ",")...)
+		return nil
+	})
+	var protectPatterns []*regexp.Regexp
+	flag.Func("protect-regex", "Leave text matching the regular expression unconverted (repeatable)", func(value string) error {
+		pattern, err := regexp.Compile(value)
+		if err != nil {
+			return err
+		}
+		protectPatterns = append(protectPatterns, pattern)
+		return nil
+	})
+	var userDicts []string
+	flag.Func("user-dict", "Text dictionary whose phrases take precedence over the preset (repeatable)", func(value string) error {
+		userDicts = append(userDicts, value)
+		return nil
+	})
 
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "OpenCC-Go %s - Chinese Conversion Tool\n\n", version)
-		fmt.Fprintf(os.Stderr, "Usage: opencc -c <preset|config-file> [options]\n\n")
+		fmt.Fprintf(os.Stderr, "Usage: opencc -c <preset|config-file> [options]\n")
+		fmt.Fprintf(os.Stderr, "       opencc <command> [arguments]\n\n")
+		fmt.Fprintf(os.Stderr, "Commands:\n")
+		fmt.Fprintf(os.Stderr, "  check -c <preset> [file...]      Report the spans a conversion would change\n")
+		fmt.Fprintf(os.Stderr, "  detect [text]                    Detect Simplified or Traditional Chinese\n")
+		fmt.Fprintf(os.Stderr, "  dict compile <in.txt> <out.bin>  Compile a dictionary to the binary format\n")
+		fmt.Fprintf(os.Stderr, "  explain -c <preset> [text]       Show which dictionary entries converted each segment\n")
+		fmt.Fprintf(os.Stderr, "  rename -c <preset> [-r] <path>   Convert file and directory names\n")
+		fmt.Fprintf(os.Stderr, "  serve --addr :8080               Run an HTTP conversion server\n\n")
 		fmt.Fprintf(os.Stderr, "Options:\n")
 		fmt.Fprintf(os.Stderr, "  -c, --config <preset|file>  Conversion preset (e.g., s2t) or config file path\n")
 		fmt.Fprintf(os.Stderr, "  -i, --input <file>         Input file (default: stdin)\n")
@@ -78,6 +160,21 @@ func main() {
 		fmt.Fprintf(os.Stderr, "  -v, --version              Show version\n")
 		fmt.Fprintf(os.Stderr, "  -h, --help                 Show this help\n")
 		fmt.Fprintf(os.Stderr, "  --list                     List all available presets\n")
+		fmt.Fprintf(os.Stderr, "  --candidates               Output all candidates of every segment as JSON lines\n")
+		fmt.Fprintf(os.Stderr, "  --protect                  Leave URLs, emails, code and HTML markup unconverted\n")
+		fmt.Fprintf(os.Stderr, "  --protect-regex <regexp>   Leave text matching the expression unconverted (repeatable)\n")
+		fmt.Fprintf(os.Stderr, "  --user-dict <file>         Text dictionary whose phrases take precedence (repeatable)\n")
+		fmt.Fprintf(os.Stderr, "  --from-encoding <enc>      Encoding of the input, or auto to detect it (default: utf-8)\n")
+		fmt.Fprintf(os.Stderr, "  --to-encoding <enc>        Encoding of the output (default: utf-8)\n")
+		fmt.Fprintf(os.Stderr, "                             Encodings: %s\n", encodingNames)
+		fmt.Fprintf(os.Stderr, "\nDirectory Options:\n")
+		fmt.Fprintf(os.Stderr, "  -r, --recursive <dir>      Convert the text files below dir into -o <dir>\n")
+		fmt.Fprintf(os.Stderr, "  --in-place                 Replace the files instead of writing to -o\n")
+		fmt.Fprintf(os.Stderr, "  --backup <suffix>          With --in-place, keep originals as file<suffix>\n")
+		fmt.Fprintf(os.Stderr, "  --include <glob>           Only convert matching files (repeatable)\n")
+		fmt.Fprintf(os.Stderr, "  --exclude <glob>           Skip matching files and directories (repeatable)\n")
+		fmt.Fprintf(os.Stderr, "  --ext <ext,...>            Only convert files with these extensions\n")
+		fmt.Fprintf(os.Stderr, "  -j, --jobs <n>             Files converted in parallel (default: number of CPUs)\n")
 		fmt.Fprintf(os.Stderr, "\nConversion Presets (embedded):\n")
 		fmt.Fprintf(os.Stderr, "  s2t    Simplified → Traditional (Mainland China)\n")
 		fmt.Fprintf(os.Stderr, "  t2s    Traditional → Simplified (Mainland China)\n")
@@ -89,6 +186,9 @@ func main() {
 		fmt.Fprintf(os.Stderr, "  tw2sp  Traditional → Simplified (Taiwan, with phrases)\n")
 		fmt.Fprintf(os.Stderr, "  jp2t   Japanese Kanji → Traditional Chinese\n")
 		fmt.Fprintf(os.Stderr, "  t2jp   Traditional Chinese → Japanese Kanji\n")
+		fmt.Fprintf(os.Stderr, "  auto2s, auto2t, auto2tw, auto2hk\n")
+		fmt.Fprintf(os.Stderr, "         Any script → Simplified, Traditional, Taiwan or Hong Kong,\n")
+		fmt.Fprintf(os.Stderr, "         detecting the script of every paragraph\n")
 		fmt.Fprintf(os.Stderr, "\nExamples:\n")
 		fmt.Fprintf(os.Stderr, "  opencc -c s2t -i input.txt -o output.txt\n")
 		fmt.Fprintf(os.Stderr, "  echo \"汉字\" | opencc -c s2t\n")
@@ -115,6 +215,12 @@ func main() {
 	if *outputLong != "" {
 		*outputFile = *outputLong
 	}
+	if *recurseLong != "" {
+		*recursive = *recurseLong
+	}
+	if *jobsLong > 0 {
+		*jobs = *jobsLong
+	}
 
 	if *showVersion {
 		fmt.Printf("OpenCC-Go %s\n", version)
@@ -128,7 +234,7 @@ func main() {
 
 	if *listConfigs {
 		fmt.Println("Available conversion presets:")
-		for _, config := range embeddata.ListConfigs() {
+		for _, config := range presetNames() {
 			fmt.Printf("  %s\n", config)
 		}
 		os.Exit(0)
@@ -140,20 +246,52 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Resolve config name to config content
-	configContent, err := resolveConfig(*configFile)
+	encodings, err := parseEncodings(*fromEnc, *toEnc)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Cannot find configuration: %s\n", *configFile)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if *candidates && !encodings.to.isUTF8() {
+		fmt.Fprintf(os.Stderr, "Error: --candidates always writes UTF-8 JSON\n")
 		os.Exit(1)
 	}
 
-	// Create converter from embedded config
-	converter, err := opencc.NewSimpleConverterFromData(configContent)
+	converter, err := newConverter(*configFile)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Failed to create converter: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
+	// Earlier user dictionaries take precedence over later ones
+	for _, filename := range userDicts {
+		d, err := opencc.LoadUserDict(filename)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Cannot load user dictionary: %v\n", err)
+			os.Exit(1)
+		}
+		converter = converter.WithUserDict(d, 1)
+	}
+
+	if *protect {
+		converter.SetProtector(segmentation.NewDefaultProtector(protectPatterns...))
+	} else if len(protectPatterns) > 0 {
+		converter.SetProtector(segmentation.NewProtector(protectPatterns...))
+	}
+
+	if *recursive != "" {
+		os.Exit(runBatch(converter, batchOptions{
+			source:       *recursive,
+			output:       *outputFile,
+			inPlace:      *inPlace,
+			backupSuffix: *backup,
+			include:      includes,
+			exclude:      excludes,
+			extensions:   extensions,
+			jobs:         *jobs,
+			encodings:    encodings,
+		}, *inputFile != "" || *candidates))
+	}
+
 	// Open input
 	var input io.Reader
 	if *inputFile == "" {
@@ -182,24 +320,19 @@ func main() {
 		output = file
 	}
 
-	// Process input line by line
-	scanner := bufio.NewScanner(input)
-	writer := bufio.NewWriter(output)
-	defer writer.Flush()
-
-	for scanner.Scan() {
-		line := scanner.Text()
-		converted := converter.Convert(line)
-		writer.WriteString(converted)
-		writer.WriteByte('\n')
-	}
-
-	if err := scanner.Err(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error reading input: %v\n", err)
+	if err := convertInput(converter, input, output, *candidates, encodings); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 }
 
+// candidatesLine is the JSON record written for each input line with
+// --candidates
+type candidatesLine struct {
+	Line     int                        `json:"line"`
+	Segments []opencc.SegmentCandidates `json:"segments"`
+}
+
 // resolveConfig resolves a config name to its JSON content
 // It supports:
 // 1. File paths (absolute or relative) - reads from disk
@@ -240,3 +373,19 @@ func resolveConfig(name string) ([]byte, error) {
 
 	return nil, os.ErrNotExist
 }
+
+// newConverter creates a converter from a preset name or config file
+func newConverter(name string) (*opencc.SimpleConverter, error) {
+	if isAutoPreset(name) {
+		return opencc.NewAutoConverter(configMappings[name])
+	}
+	configContent, err := resolveConfig(name)
+	if err != nil {
+		return nil, fmt.Errorf("cannot find configuration: %s", name)
+	}
+	converter, err := opencc.NewSimpleConverterFromData(configContent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create converter: %w", err)
+	}
+	return converter, nil
+}
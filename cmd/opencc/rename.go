@@ -0,0 +1,215 @@
+/*
+ * Open Chinese Convert
+ *
+ * Copyright 2010-2014 Carbo Kuo <byvoid@byvoid.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/yanmingcao/opencc-go"
+)
+
+func renameUsage() {
+	fmt.Fprintf(os.Stderr, "Usage: opencc rename -c <preset|config-file> [options] <path>...\n\n")
+	fmt.Fprintf(os.Stderr, "Converts the names of files and directories. Entries inside a directory\n")
+	fmt.Fprintf(os.Stderr, "are renamed before the directories above them. Nothing is renamed if two names\n")
+	fmt.Fprintf(os.Stderr, "would convert to the same target or a target already exists.\n\n")
+	fmt.Fprintf(os.Stderr, "Options:\n")
+	fmt.Fprintf(os.Stderr, "  -c, --config <preset|file>  Conversion preset (e.g., s2t) or config file path\n")
+	fmt.Fprintf(os.Stderr, "  -r, --recursive             Also rename the contents of directories\n")
+	fmt.Fprintf(os.Stderr, "  -n, --dry-run               Print the renames without performing them\n")
+}
+
+// runRename implements the "opencc rename" subcommand
+func runRename(args []string) int {
+	flags := flag.NewFlagSet("rename", flag.ContinueOnError)
+	flags.Usage = renameUsage
+	var configFile string
+	var recursive, dryRun bool
+	flags.StringVar(&configFile, "c", "", "Conversion preset or config file")
+	flags.StringVar(&configFile, "config", "", "Conversion preset or config file")
+	flags.BoolVar(&recursive, "r", false, "Rename the contents of directories")
+	flags.BoolVar(&recursive, "recursive", false, "Rename the contents of directories")
+	flags.BoolVar(&dryRun, "n", false, "Print the renames without performing them")
+	flags.BoolVar(&dryRun, "dry-run", false, "Print the renames without performing them")
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 1
+	}
+	if configFile == "" || flags.NArg() == 0 {
+		fmt.Fprintf(os.Stderr, "Error: A conversion preset (-c or --config) and at least one path are required\n\n")
+		renameUsage()
+		return 1
+	}
+
+	converter, err := newConverter(configFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	ops, err := planRenames(converter, flags.Args(), recursive)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if collisions := findCollisions(ops); len(collisions) > 0 {
+		for _, collision := range collisions {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", collision)
+		}
+		fmt.Fprintf(os.Stderr, "Nothing renamed\n")
+		return 1
+	}
+
+	for _, op := range ops {
+		if !dryRun {
+			if err := os.Rename(op.From, op.To); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				return 1
+			}
+		}
+		fmt.Printf("%s -> %s\n", op.From, op.To)
+	}
+	if dryRun {
+		fmt.Fprintf(os.Stderr, "Dry run, nothing renamed\n")
+	}
+	return 0
+}
+
+// renameOp renames one file or directory
+type renameOp struct {
+	From string
+	To   string
+}
+
+// planRenames lists the renames that convert the names of paths and, if
+// recursive, of everything below them. Deeper paths come before shallower
+// ones, so every path is valid when its turn comes even if paths overlap.
+func planRenames(converter *opencc.SimpleConverter, paths []string, recursive bool) ([]renameOp, error) {
+	var ops []renameOp
+	// seen holds the paths already visited, in case paths overlap
+	seen := make(map[string]bool)
+	var visit func(path string, info os.FileInfo) error
+	visit = func(path string, info os.FileInfo) error {
+		key, err := filepath.Abs(path)
+		if err != nil {
+			return err
+		}
+		if seen[key] {
+			return nil
+		}
+		seen[key] = true
+		if recursive && info.IsDir() {
+			entries, err := os.ReadDir(path)
+			if err != nil {
+				return err
+			}
+			for _, entry := range entries {
+				// Symlinks are renamed but not followed
+				entryInfo, err := entry.Info()
+				if err != nil {
+					return err
+				}
+				if err := visit(filepath.Join(path, entry.Name()), entryInfo); err != nil {
+					return err
+				}
+			}
+		}
+
+		dir, name := filepath.Split(path)
+		if name == "" || name == "." || name == ".." {
+			return nil
+		}
+		if converted := converter.Convert(name); converted != name {
+			ops = append(ops, renameOp{From: path, To: filepath.Join(dir, converted)})
+		}
+		return nil
+	}
+
+	for _, path := range paths {
+		path = filepath.Clean(path)
+		info, err := os.Lstat(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := visit(path, info); err != nil {
+			return nil, err
+		}
+	}
+	// A path given after its directory is renamed first too
+	sort.SliceStable(ops, func(i, j int) bool {
+		return pathDepth(ops[i].From) > pathDepth(ops[j].From)
+	})
+	return ops, nil
+}
+
+// pathDepth returns the number of directories above path
+func pathDepth(path string) int {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	return strings.Count(path, string(filepath.Separator))
+}
+
+// findCollisions describes the renames whose target is shared with another
+// rename or already exists
+func findCollisions(ops []renameOp) []string {
+	sources := make(map[string][]string)
+	var targets []string
+	for _, op := range ops {
+		if len(sources[op.To]) == 0 {
+			targets = append(targets, op.To)
+		}
+		sources[op.To] = append(sources[op.To], op.From)
+	}
+
+	var collisions []string
+	for _, target := range targets {
+		from := sources[target]
+		if len(from) > 1 {
+			sort.Strings(from)
+			collisions = append(collisions, fmt.Sprintf("%s would all be renamed to %s", strings.Join(from, ", "), target))
+			continue
+		}
+		if exists(target) && !sameFile(from[0], target) {
+			collisions = append(collisions, fmt.Sprintf("cannot rename %s: %s already exists", from[0], target))
+		}
+	}
+	return collisions
+}
+
+// exists reports whether a file, directory or symlink exists at path
+func exists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
+
+// sameFile reports whether a and b are the same file, as they are when a
+// file system normalizes names
+func sameFile(a, b string) bool {
+	infoA, errA := os.Lstat(a)
+	infoB, errB := os.Lstat(b)
+	return errA == nil && errB == nil && os.SameFile(infoA, infoB)
+}
@@ -0,0 +1,93 @@
+/*
+ * Open Chinese Convert
+ *
+ * Copyright 2010-2014 Carbo Kuo <byvoid@byvoid.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createFiles(t *testing.T, dir string, names ...string) {
+	for _, name := range names {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, nil, 0644))
+	}
+}
+
+func TestPlanRenames(t *testing.T) {
+	converter, err := newConverter("s2t")
+	require.NoError(t, err)
+	dir := t.TempDir()
+	createFiles(t, dir, "简体目录/子目录/汉字.txt", "简体目录/发.txt", "ok.txt")
+	root := filepath.Join(dir, "简体目录")
+
+	ops, err := planRenames(converter, []string{root}, false)
+	require.NoError(t, err)
+	assert.Equal(t, []renameOp{{From: root, To: filepath.Join(dir, "簡體目錄")}}, ops)
+
+	// Overlapping arguments rename the deeper path first
+	sub := filepath.Join(root, "子目录")
+	ops, err = planRenames(converter, []string{root, filepath.Join(sub, "汉字.txt")}, false)
+	require.NoError(t, err)
+	assert.Equal(t, []renameOp{
+		{From: filepath.Join(sub, "汉字.txt"), To: filepath.Join(sub, "漢字.txt")},
+		{From: root, To: filepath.Join(dir, "簡體目錄")},
+	}, ops)
+
+	// Entries come before their directory, and paths given twice are
+	// planned once
+	ops, err = planRenames(converter, []string{dir, root + string(filepath.Separator), sub}, true)
+	require.NoError(t, err)
+	assert.Equal(t, []renameOp{
+		{From: filepath.Join(sub, "汉字.txt"), To: filepath.Join(sub, "漢字.txt")},
+		{From: filepath.Join(root, "发.txt"), To: filepath.Join(root, "發.txt")},
+		{From: sub, To: filepath.Join(root, "子目錄")},
+		{From: root, To: filepath.Join(dir, "簡體目錄")},
+	}, ops)
+	assert.Empty(t, findCollisions(ops))
+
+	for _, op := range ops {
+		require.NoError(t, os.Rename(op.From, op.To))
+	}
+	assert.FileExists(t, filepath.Join(dir, "簡體目錄", "子目錄", "漢字.txt"))
+	assert.FileExists(t, filepath.Join(dir, "簡體目錄", "發.txt"))
+
+	_, err = planRenames(converter, []string{filepath.Join(dir, "missing")}, false)
+	assert.Error(t, err)
+}
+
+func TestFindCollisions(t *testing.T) {
+	converter, err := newConverter("t2s")
+	require.NoError(t, err)
+	dir := t.TempDir()
+	createFiles(t, dir, "發.txt", "髮.txt", "後.txt", "后.txt", "體.txt")
+
+	ops, err := planRenames(converter, []string{dir}, true)
+	require.NoError(t, err)
+	require.Len(t, ops, 4)
+	assert.Equal(t, []string{
+		"cannot rename " + filepath.Join(dir, "後.txt") + ": " + filepath.Join(dir, "后.txt") + " already exists",
+		filepath.Join(dir, "發.txt") + ", " + filepath.Join(dir, "髮.txt") + " would all be renamed to " + filepath.Join(dir, "发.txt"),
+	}, findCollisions(ops))
+}
@@ -0,0 +1,287 @@
+/*
+ * Open Chinese Convert
+ *
+ * Copyright 2010-2014 Carbo Kuo <byvoid@byvoid.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/yanmingcao/opencc-go"
+	"github.com/yanmingcao/opencc-go/pkg/embeddata"
+)
+
+const (
+	// defaultMaxBodySize is the default limit on the size of a request body
+	defaultMaxBodySize = 1 << 20
+	// shutdownTimeout is how long in-flight requests get to finish on
+	// shutdown
+	shutdownTimeout = 10 * time.Second
+)
+
+func serveUsage() {
+	fmt.Fprintf(os.Stderr, "Usage: opencc serve [options]\n\n")
+	fmt.Fprintf(os.Stderr, "Runs an HTTP conversion server with the embedded presets.\n\n")
+	fmt.Fprintf(os.Stderr, "Endpoints:\n")
+	fmt.Fprintf(os.Stderr, "  POST /convert?config=<preset>  Convert the plain text body, or a JSON body\n")
+	fmt.Fprintf(os.Stderr, "                                 {\"text\": ..., \"config\": ...}\n")
+	fmt.Fprintf(os.Stderr, "  GET  /presets                  List the available presets\n")
+	fmt.Fprintf(os.Stderr, "  GET  /health                   Report health and version\n\n")
+	fmt.Fprintf(os.Stderr, "Options:\n")
+	fmt.Fprintf(os.Stderr, "  --addr <address>            Listen address (default: :8080)\n")
+	fmt.Fprintf(os.Stderr, "  -c, --config <preset>       Preset used when a request names none (default: s2t)\n")
+	fmt.Fprintf(os.Stderr, "  --max-body <bytes>          Maximum request body size (default: %d)\n", defaultMaxBodySize)
+}
+
+// runServe implements the "opencc serve" subcommand
+func runServe(args []string) int {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	flags.Usage = serveUsage
+	var (
+		addr          string
+		defaultConfig string
+		maxBodySize   int64
+	)
+	flags.StringVar(&addr, "addr", ":8080", "Listen address")
+	flags.StringVar(&defaultConfig, "c", "s2t", "Default preset")
+	flags.StringVar(&defaultConfig, "config", "s2t", "Default preset")
+	flags.Int64Var(&maxBodySize, "max-body", defaultMaxBodySize, "Maximum request body size")
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 1
+	}
+
+	s := newServer(defaultConfig, maxBodySize)
+	// Fail early on a bad default preset rather than on every request
+	if _, err := s.converter(defaultConfig); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           s.handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errc := make(chan error, 1)
+	go func() {
+		fmt.Fprintf(os.Stderr, "Listening on %s\n", addr)
+		errc <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errc:
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	case <-ctx.Done():
+	}
+
+	// Stop accepting connections and let in-flight requests finish
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: shutdown: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// server serves conversions with the embedded presets
+type server struct {
+	defaultConfig string
+	maxBodySize   int64
+
+	mu         sync.Mutex
+	converters map[string]*cachedConverter
+}
+
+// cachedConverter is a converter created once and shared by all requests
+// for its preset
+type cachedConverter struct {
+	once      sync.Once
+	converter *opencc.SimpleConverter
+	err       error
+}
+
+// convertRequest is the JSON body of a conversion request
+type convertRequest struct {
+	Text   string `json:"text"`
+	Config string `json:"config,omitempty"`
+}
+
+// convertResponse is the JSON response to a conversion request
+type convertResponse struct {
+	Text   string `json:"text"`
+	Config string `json:"config"`
+}
+
+// errorResponse is the JSON body of an error response
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// newServer creates a server using defaultConfig for requests without a
+// preset and rejecting bodies larger than maxBodySize
+func newServer(defaultConfig string, maxBodySize int64) *server {
+	return &server{
+		defaultConfig: defaultConfig,
+		maxBodySize:   maxBodySize,
+		converters:    make(map[string]*cachedConverter),
+	}
+}
+
+// handler returns the HTTP handler of the server
+func (s *server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/convert", s.handleConvert)
+	mux.HandleFunc("/presets", s.handlePresets)
+	mux.HandleFunc("/health", s.handleHealth)
+	return mux
+}
+
+// converter returns the cached converter of an embedded or automatic
+// preset, creating it on first use. Config files on disk are not served.
+func (s *server) converter(name string) (*opencc.SimpleConverter, error) {
+	if strings.ContainsAny(name, "/\\") || !embeddata.ConfigExists(name) && !isAutoPreset(name) {
+		return nil, fmt.Errorf("unknown preset: %s", name)
+	}
+
+	s.mu.Lock()
+	cached, ok := s.converters[name]
+	if !ok {
+		cached = &cachedConverter{}
+		s.converters[name] = cached
+	}
+	s.mu.Unlock()
+
+	cached.once.Do(func() {
+		cached.converter, cached.err = newConverter(name)
+	})
+	return cached.converter, cached.err
+}
+
+// handleConvert converts a plain text or JSON request body
+func (s *server) handleConvert(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, s.maxBodySize))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("request body larger than %d bytes", s.maxBodySize))
+			return
+		}
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	isJSON := mediaType == "application/json"
+	req := convertRequest{Config: r.URL.Query().Get("config")}
+	if isJSON {
+		var decoded convertRequest
+		if err := json.Unmarshal(body, &decoded); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+			return
+		}
+		req.Text = decoded.Text
+		if decoded.Config != "" {
+			req.Config = decoded.Config
+		}
+	} else {
+		req.Text = string(body)
+	}
+	if req.Config == "" {
+		req.Config = s.defaultConfig
+	}
+
+	converter, err := s.converter(req.Config)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	converted := converter.Convert(req.Text)
+
+	if isJSON {
+		writeJSON(w, http.StatusOK, convertResponse{Text: converted, Config: req.Config})
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	io.WriteString(w, converted)
+}
+
+// handlePresets lists the embedded and automatic presets
+func (s *server) handlePresets(w http.ResponseWriter, r *http.Request) {
+	if !allowGet(w, r) {
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string][]string{"presets": presetNames()})
+}
+
+// handleHealth reports that the server is up, with its version
+func (s *server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	if !allowGet(w, r) {
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok", "version": version})
+}
+
+// allowGet rejects requests other than GET and HEAD
+func allowGet(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		return true
+	}
+	w.Header().Set("Allow", "GET, HEAD")
+	writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	return false
+}
+
+// writeJSON writes v as a JSON response
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(v)
+}
+
+// writeError writes a JSON error response
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, errorResponse{Error: message})
+}
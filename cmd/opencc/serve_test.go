@@ -0,0 +1,135 @@
+/*
+ * Open Chinese Convert
+ *
+ * Copyright 2010-2014 Carbo Kuo <byvoid@byvoid.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestServer(t *testing.T, maxBodySize int64) *httptest.Server {
+	ts := httptest.NewServer(newServer("s2t", maxBodySize).handler())
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func readBody(t *testing.T, resp *http.Response) string {
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestServeConvertPlain(t *testing.T) {
+	ts := newTestServer(t, defaultMaxBodySize)
+
+	resp, err := http.Post(ts.URL+"/convert", "text/plain", strings.NewReader("简体汉字"))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/plain; charset=utf-8", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "簡體漢字", readBody(t, resp))
+
+	resp, err = http.Post(ts.URL+"/convert?config=t2s", "text/plain", strings.NewReader("簡體漢字"))
+	require.NoError(t, err)
+	assert.Equal(t, "简体汉字", readBody(t, resp))
+}
+
+func TestServeConvertJSON(t *testing.T) {
+	ts := newTestServer(t, defaultMaxBodySize)
+
+	resp, err := http.Post(ts.URL+"/convert", "application/json; charset=utf-8", strings.NewReader(`{"text": "汉字<b>", "config": "s2t"}`))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	var result convertResponse
+	require.NoError(t, json.Unmarshal([]byte(readBody(t, resp)), &result))
+	assert.Equal(t, convertResponse{Text: "漢字<b>", Config: "s2t"}, result)
+
+	// The query selects the preset when the body names none
+	resp, err = http.Post(ts.URL+"/convert?config=t2s", "application/json", strings.NewReader(`{"text": "漢字"}`))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal([]byte(readBody(t, resp)), &result))
+	assert.Equal(t, convertResponse{Text: "汉字", Config: "t2s"}, result)
+
+	resp, err = http.Post(ts.URL+"/convert", "application/json", strings.NewReader(`{"text":`))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Contains(t, readBody(t, resp), "invalid JSON")
+}
+
+func TestServeConvertErrors(t *testing.T) {
+	ts := newTestServer(t, 16)
+
+	resp, err := http.Post(ts.URL+"/convert", "text/plain", strings.NewReader(strings.Repeat("汉", 6)))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+	assert.Contains(t, readBody(t, resp), "larger than 16 bytes")
+
+	for _, config := range []string{"nope", "../data/config/s2t.json", "data/config/s2t.json"} {
+		resp, err = http.Post(ts.URL+"/convert?config="+config, "text/plain", strings.NewReader("汉"))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, config)
+		assert.JSONEq(t, `{"error": "unknown preset: `+config+`"}`, readBody(t, resp))
+	}
+
+	resp, err = http.Get(ts.URL + "/convert")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+	assert.Equal(t, http.MethodPost, resp.Header.Get("Allow"))
+	resp.Body.Close()
+}
+
+func TestServePresetsAndHealth(t *testing.T) {
+	ts := newTestServer(t, defaultMaxBodySize)
+
+	resp, err := http.Get(ts.URL + "/presets")
+	require.NoError(t, err)
+	var presets map[string][]string
+	require.NoError(t, json.Unmarshal([]byte(readBody(t, resp)), &presets))
+	assert.Contains(t, presets["presets"], "s2t")
+	assert.IsIncreasing(t, presets["presets"])
+
+	resp, err = http.Get(ts.URL + "/health")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"status": "ok", "version": "`+version+`"}`, readBody(t, resp))
+
+	resp, err = http.Post(ts.URL+"/health", "text/plain", nil)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+	resp.Body.Close()
+}
+
+func TestServeCachesConverters(t *testing.T) {
+	s := newServer("s2t", defaultMaxBodySize)
+	first, err := s.converter("s2t")
+	require.NoError(t, err)
+	second, err := s.converter("s2t")
+	require.NoError(t, err)
+	assert.Same(t, first, second)
+
+	_, err = s.converter("nope")
+	assert.Error(t, err)
+	assert.NotContains(t, s.converters, "nope")
+}
@@ -0,0 +1,295 @@
+/*
+ * Open Chinese Convert
+ *
+ * Copyright 2010-2014 Carbo Kuo <byvoid@byvoid.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package opencc
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"sort"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/yanmingcao/opencc-go/pkg/dict"
+	"github.com/yanmingcao/opencc-go/pkg/embeddata"
+)
+
+// Script is the Chinese script a text is written in
+type Script string
+
+// Scripts reported by Detect
+const (
+	ScriptSimplified  Script = "simplified"
+	ScriptTraditional Script = "traditional"
+	// ScriptMixed is text with a fair share of both scripts
+	ScriptMixed Script = "mixed"
+	// ScriptNeither is text without any character specific to one script
+	ScriptNeither Script = "neither"
+)
+
+// Variant is a regional form of Traditional Chinese characters
+type Variant string
+
+// Variants reported by Detect, named as in the conversion presets
+const (
+	VariantTaiwan   Variant = "tw"
+	VariantHongKong Variant = "hk"
+	// VariantJapanese is the shinjitai of Japanese kanji
+	VariantJapanese Variant = "jp"
+)
+
+// mixedShare is the share of characters of the less frequent script at
+// which text counts as mixed
+const mixedShare = 0.1
+
+// VariantHint reports characters written in the form of a variant
+type VariantHint struct {
+	Variant Variant `json:"variant"`
+	// Count is the number of characters in the form of the variant
+	Count      int     `json:"count"`
+	Confidence float64 `json:"confidence"`
+}
+
+// Detection is the result of Detect
+type Detection struct {
+	Script     Script  `json:"script"`
+	Confidence float64 `json:"confidence"`
+	// Simplified and Traditional count the characters that are only used in
+	// one script; Han counts all Chinese characters
+	Simplified  int `json:"simplified"`
+	Traditional int `json:"traditional"`
+	Han         int `json:"han"`
+	// Hints lists the variants whose forms occur in the text, most likely
+	// first
+	Hints []VariantHint `json:"hints,omitempty"`
+}
+
+// variantForms holds the characters that tell a variant apart from the
+// standard traditional characters of OpenCC
+type variantForms struct {
+	variant Variant
+	// forms are characters the variant writes differently
+	forms map[rune]bool
+	// standard are the characters the variant replaces
+	standard map[rune]bool
+	// traditionalOnly limits hints to text with traditional characters
+	traditionalOnly bool
+}
+
+// detector scores text against the character dictionaries
+type detector struct {
+	simplified  map[rune]bool
+	traditional map[rune]bool
+	variants    []*variantForms
+}
+
+// defaultDetector is built from the embedded dictionaries on first use
+var defaultDetector = sync.OnceValues(func() (*detector, error) {
+	load := func(name string) (*dict.Lexicon, error) {
+		content, err := embeddata.GetDict(name)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", err, name)
+		}
+		return dict.ParseLexiconFromReader(bufio.NewReader(bytes.NewReader(content)))
+	}
+	return newDetector(load)
+})
+
+// Detect tells whether text is written in Simplified or Traditional
+// Chinese, both or neither, using the characters that the STCharacters and
+// TSCharacters dictionaries convert. Hints name the regional variants
+// whose forms of characters, per the TWVariants, HKVariants and JPVariants
+// dictionaries, occur in the text.
+func Detect(text string) Detection {
+	d, err := defaultDetector()
+	if err != nil {
+		panic(fmt.Sprintf("opencc: loading embedded dictionaries: %v", err))
+	}
+	return d.detect(text)
+}
+
+// newDetector builds a detector from the dictionaries returned by load
+func newDetector(load func(name string) (*dict.Lexicon, error)) (*detector, error) {
+	d := &detector{
+		simplified:  make(map[rune]bool),
+		traditional: make(map[rune]bool),
+	}
+
+	st, err := load("STCharacters")
+	if err != nil {
+		return nil, err
+	}
+	ts, err := load("TSCharacters")
+	if err != nil {
+		return nil, err
+	}
+	// Characters that standard traditional text uses, such as 吃 for 喫
+	standardTraditional := make(map[rune]bool)
+	forEachChange(st, func(from rune, to []rune, changed bool) {
+		if changed {
+			d.simplified[from] = true
+		}
+		for _, r := range to {
+			standardTraditional[r] = true
+		}
+	})
+	forEachChange(ts, func(from rune, to []rune, changed bool) {
+		if changed {
+			d.traditional[from] = true
+		}
+	})
+
+	for _, v := range []struct {
+		variant         Variant
+		name            string
+		traditionalOnly bool
+	}{
+		{VariantTaiwan, "TWVariants", true},
+		{VariantHongKong, "HKVariants", true},
+		{VariantJapanese, "JPVariants", false},
+	} {
+		lexicon, err := load(v.name)
+		if err != nil {
+			return nil, err
+		}
+		forms := &variantForms{
+			variant:         v.variant,
+			forms:           make(map[rune]bool),
+			standard:        make(map[rune]bool),
+			traditionalOnly: v.traditionalOnly,
+		}
+		forEachChange(lexicon, func(from rune, to []rune, changed bool) {
+			if changed {
+				forms.standard[from] = true
+			}
+			for _, r := range to {
+				if r == from || standardTraditional[r] {
+					continue
+				}
+				// Shinjitai shared with simplified characters, such as 国,
+				// say nothing about Japanese
+				if !v.traditionalOnly && d.simplified[r] {
+					continue
+				}
+				forms.forms[r] = true
+			}
+		})
+		d.variants = append(d.variants, forms)
+	}
+	// Regional forms such as 床 and 户 are simplified characters to
+	// STCharacters, but are not evidence of Simplified Chinese
+	for _, v := range d.variants {
+		if v.traditionalOnly {
+			for r := range v.forms {
+				delete(d.simplified, r)
+			}
+		}
+	}
+	return d, nil
+}
+
+// forEachChange calls fn for every single-character entry of lexicon with
+// its values and whether the entry changes the character
+func forEachChange(lexicon *dict.Lexicon, fn func(from rune, to []rune, changed bool)) {
+	for _, entry := range lexicon.Entries() {
+		from, size := utf8.DecodeRuneInString(entry.Key())
+		if size != len(entry.Key()) {
+			continue
+		}
+		changed := true
+		var to []rune
+		for _, value := range entry.Values() {
+			r, size := utf8.DecodeRuneInString(value)
+			if size != len(value) {
+				continue
+			}
+			changed = changed && r != from
+			to = append(to, r)
+		}
+		fn(from, to, changed)
+	}
+}
+
+// evidence weighs a count of n characters: one character is weak evidence,
+// dozens are strong
+func evidence(n int) float64 {
+	return float64(n) / float64(n+1)
+}
+
+// detect implements Detect
+func (d *detector) detect(text string) Detection {
+	var result Detection
+	forms := make([]int, len(d.variants))
+	standard := make([]int, len(d.variants))
+	for _, r := range text {
+		if !unicode.Is(unicode.Han, r) {
+			continue
+		}
+		result.Han++
+		switch simplified, traditional := d.simplified[r], d.traditional[r]; {
+		case simplified && !traditional:
+			result.Simplified++
+		case traditional && !simplified:
+			result.Traditional++
+		}
+		for i, v := range d.variants {
+			if v.forms[r] {
+				forms[i]++
+			} else if v.standard[r] {
+				standard[i]++
+			}
+		}
+	}
+
+	s, t := result.Simplified, result.Traditional
+	switch {
+	case s+t == 0:
+		result.Script = ScriptNeither
+		result.Confidence = 1
+		if result.Han > 0 {
+			result.Confidence = evidence(result.Han)
+		}
+	case float64(min(s, t)) >= mixedShare*float64(s+t):
+		result.Script = ScriptMixed
+		result.Confidence = evidence(s+t) * min(1, 2*float64(min(s, t))/float64(s+t))
+	default:
+		result.Script = ScriptSimplified
+		if t > s {
+			result.Script = ScriptTraditional
+		}
+		result.Confidence = evidence(s+t) * float64(max(s, t)) / float64(s+t)
+	}
+
+	hasTraditional := result.Script == ScriptTraditional || result.Script == ScriptMixed
+	for i, v := range d.variants {
+		if forms[i] == 0 || v.traditionalOnly && !hasTraditional {
+			continue
+		}
+		result.Hints = append(result.Hints, VariantHint{
+			Variant:    v.variant,
+			Count:      forms[i],
+			Confidence: evidence(forms[i]) * float64(forms[i]) / float64(forms[i]+standard[i]),
+		})
+	}
+	sort.SliceStable(result.Hints, func(i, j int) bool {
+		return result.Hints[i].Confidence > result.Hints[j].Confidence
+	})
+	return result
+}
@@ -0,0 +1,78 @@
+/*
+ * Open Chinese Convert
+ *
+ * Copyright 2010-2014 Carbo Kuo <byvoid@byvoid.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package opencc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		script Script
+	}{
+		{"simplified", "这是一个简体中文的例子，我们来看看。", ScriptSimplified},
+		{"traditional", "這是一個繁體中文的例子，我們來看看。", ScriptTraditional},
+		{"mixed", "简体和繁體混合的文字內容", ScriptMixed},
+		{"shared characters", "中文", ScriptNeither},
+		{"no chinese", "hello, world", ScriptNeither},
+		{"empty", "", ScriptNeither},
+		// Regional forms are not taken for simplified characters
+		{"taiwan", "為什麼裡面有這麼多床", ScriptTraditional},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := Detect(tt.text)
+			assert.Equal(t, tt.script, d.Script)
+			assert.True(t, d.Confidence > 0 && d.Confidence <= 1, "confidence %v", d.Confidence)
+		})
+	}
+
+	d := Detect("这是一个简体中文的例子，我们来看看。")
+	assert.Equal(t, 0, d.Traditional)
+	assert.Equal(t, 16, d.Han)
+	assert.Greater(t, d.Confidence, 0.8)
+
+	// More evidence gives more confidence
+	assert.Greater(t, Detect("汉字汉字汉字").Confidence, Detect("汉字").Confidence)
+	// A few characters of the other script still count as one script
+	assert.Equal(t, ScriptTraditional, Detect("這是一個繁體中文的例子，我們來看看，還有後來說過的話与").Script)
+}
+
+func TestDetectVariants(t *testing.T) {
+	hints := Detect("為什麼裡面有這麼多床").Hints
+	if assert.NotEmpty(t, hints) {
+		assert.Equal(t, VariantTaiwan, hints[0].Variant)
+		assert.Equal(t, 3, hints[0].Count)
+	}
+
+	// OpenCC standard forms are not Taiwanese
+	assert.Empty(t, Detect("爲什麼裏面有這麼多牀").Hints)
+
+	hints = Detect("仏教の伝来").Hints
+	if assert.Len(t, hints, 1) {
+		assert.Equal(t, VariantJapanese, hints[0].Variant)
+	}
+
+	// Regional forms of Traditional Chinese are ignored in simplified text
+	assert.Empty(t, Detect("床上有户口本").Hints)
+}
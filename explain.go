@@ -0,0 +1,64 @@
+/*
+ * Open Chinese Convert
+ *
+ * Copyright 2010-2014 Carbo Kuo <byvoid@byvoid.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package opencc
+
+import (
+	"github.com/yanmingcao/opencc-go/pkg/conversion"
+)
+
+// Explanation describes how one segment of the input was converted
+type Explanation struct {
+	Source string
+	Target string
+	// Steps holds the trace of each step of the conversion chain
+	Steps []conversion.Trace
+}
+
+// Explain converts the input text and explains, for every segment, which
+// dictionary entry each conversion step matched and what it chose
+func (c *Converter) Explain(text string) []Explanation {
+	if len(text) == 0 {
+		return nil
+	}
+
+	segments := c.segment(text)
+	converted, traces := c.conversionChain.ConvertWithTrace(segments)
+
+	explanations := make([]Explanation, segments.Length())
+	for i := range explanations {
+		explanations[i] = Explanation{
+			Source: segments.At(i),
+			Target: converted.At(i),
+			Steps:  make([]conversion.Trace, len(traces)),
+		}
+		for step := range traces {
+			explanations[i].Steps[step] = traces[step][i]
+		}
+	}
+	return explanations
+}
+
+// Explain converts the input text and explains how every segment was
+// converted
+func (s *SimpleConverter) Explain(text string) []Explanation {
+	if s.auto != nil {
+		return s.auto.explain(text)
+	}
+	return s.converter.Explain(text)
+}
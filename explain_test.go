@@ -0,0 +1,75 @@
+/*
+ * Open Chinese Convert
+ *
+ * Copyright 2010-2014 Carbo Kuo <byvoid@byvoid.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package opencc
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yanmingcao/opencc-go/pkg/config"
+)
+
+func TestExplain(t *testing.T) {
+	dir := t.TempDir()
+	phrases := "# phrases\n软件\t軟件\n"
+	chars := "软\t軟\n件\t件\n"
+	tw := "軟件\t軟體\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "phrases.txt"), []byte(phrases), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "chars.txt"), []byte(chars), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "tw.txt"), []byte(tw), 0644))
+
+	group := &config.DictConfig{Type: "group", Dicts: []*config.DictConfig{
+		{Type: "text", File: "phrases.txt"},
+		{Type: "text", File: "chars.txt"},
+	}}
+	cfg := &config.Config{
+		Name:         "test",
+		Segmentation: &config.SegmentationConfig{Type: config.SegmentationTypeMMseg, Dict: group},
+		ConversionChain: []*config.ConversionStepConfig{
+			{Dict: group},
+			{Dict: &config.DictConfig{Type: "text", File: "tw.txt"}},
+		},
+	}
+	converter, err := NewSimpleConverterFromConfig(cfg, dir)
+	require.NoError(t, err)
+
+	explanations := converter.Explain("软件x")
+	require.Len(t, explanations, 2)
+
+	e := explanations[0]
+	assert.Equal(t, "软件", e.Source)
+	assert.Equal(t, "軟體", e.Target)
+	require.Len(t, e.Steps, 2)
+	assert.Equal(t, "軟件", e.Steps[0].Output)
+	assert.Equal(t, filepath.Join(dir, "phrases.txt")+":2", e.Steps[0].Source.String())
+	assert.Equal(t, "軟件", e.Steps[1].Input)
+	assert.Equal(t, "軟體", e.Steps[1].Output)
+	require.NotNil(t, e.Steps[1].Entry)
+	assert.Equal(t, []string{"軟體"}, e.Steps[1].Entry.Values())
+	assert.Equal(t, 1, e.Steps[1].Source.Line)
+
+	e = explanations[1]
+	assert.Equal(t, "x", e.Target)
+	assert.Nil(t, e.Steps[0].Entry)
+	assert.Empty(t, e.Steps[0].Source.File)
+}
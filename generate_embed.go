@@ -15,6 +15,7 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 )
 
@@ -41,6 +42,7 @@ func main() {
 
 	// Read dictionary files from data/dictionary/
 	dictsDir := "data/dictionary"
+	dictData := make(map[string]string)
 	filepath.WalkDir(dictsDir, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
@@ -52,6 +54,39 @@ func main() {
 				return err
 			}
 			dicts = append(dicts, fmt.Sprintf(`"%s": %#v`, name, string(data)))
+			dictData[name] = string(data)
+		}
+		return nil
+	})
+	var variantsWithoutRev []string
+	for name := range dictData {
+		if _, ok := dictData[name+"Rev"]; strings.HasSuffix(name, "Variants") && !ok {
+			variantsWithoutRev = append(variantsWithoutRev, name)
+		}
+	}
+	sort.Strings(variantsWithoutRev)
+
+	// Upstream builds the reverse of every variants dictionary; derive the
+	// ones data/dictionary does not ship
+	for _, name := range variantsWithoutRev {
+		dicts = append(dicts, fmt.Sprintf(`"%s": %#v`, name+"Rev", reverseDict(dictData[name])))
+	}
+	sort.Strings(dicts)
+
+	// Read scheme files from data/scheme/
+	var schemes []string
+	schemesDir := "data/scheme"
+	filepath.WalkDir(schemesDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && strings.HasSuffix(path, ".txt") {
+			name := strings.TrimSuffix(filepath.Base(path), ".txt")
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			schemes = append(schemes, fmt.Sprintf(`"%s": %#v`, name, string(data)))
 		}
 		return nil
 	})
@@ -143,4 +178,56 @@ func GetConfigString(name string) (string, bool) {
 		os.Exit(1)
 	}
 	fmt.Println("Generated pkg/embeddata/embeddata.go with", len(configs), "configs and", len(dicts), "dictionaries")
+
+	// Generate pkg/embeddata/schemedata.go
+	buf.Reset()
+	buf.WriteString(`// Code generated by generate_embed.go. DO NOT EDIT.
+
+package embeddata
+
+// EmbeddedScheme holds all embedded scheme files
+var EmbeddedScheme = map[string]string{
+`)
+	for _, s := range schemes {
+		buf.WriteString("\t")
+		buf.WriteString(s)
+		buf.WriteString(",\n")
+	}
+	buf.WriteString("}\n")
+
+	err = os.WriteFile("pkg/embeddata/schemedata.go", buf.Bytes(), 0644)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing pkg/embeddata/schemedata.go: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println("Generated pkg/embeddata/schemedata.go with", len(schemes), "schemes")
+}
+
+// reverseDict maps every value of a text dictionary back to its keys, in
+// the order they appear
+func reverseDict(data string) string {
+	var values []string
+	keys := make(map[string][]string)
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, list, ok := strings.Cut(line, "\t")
+		if !ok {
+			continue
+		}
+		for _, value := range strings.Fields(list) {
+			if len(keys[value]) == 0 {
+				values = append(values, value)
+			}
+			keys[value] = append(keys[value], key)
+		}
+	}
+
+	var buf strings.Builder
+	for _, value := range values {
+		buf.WriteString(value + "\t" + strings.Join(keys[value], " ") + "\n")
+	}
+	return buf.String()
 }
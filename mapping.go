@@ -0,0 +1,77 @@
+/*
+ * Open Chinese Convert
+ *
+ * Copyright 2010-2014 Carbo Kuo <byvoid@byvoid.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package opencc
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Span is a half-open range [Start, End) of offsets
+type Span struct {
+	Start int
+	End   int
+}
+
+// Alignment maps a segment of the source text to its converted form
+type Alignment struct {
+	Source      Span // byte range in the source text
+	Target      Span // byte range in the converted text
+	SourceRunes Span // rune range in the source text
+	TargetRunes Span // rune range in the converted text
+}
+
+// ConvertWithMapping converts the input text and returns the alignment of
+// every segment between the source and the converted text
+func (c *Converter) ConvertWithMapping(text string) (string, []Alignment) {
+	if len(text) == 0 {
+		return text, nil
+	}
+
+	segments := c.segment(text)
+	// Every conversion step maps segment i of its input to segment i of
+	// its output, so the segment boundaries hold through the whole chain
+	converted := c.conversionChain.Convert(segments)
+
+	var builder strings.Builder
+	alignments := make([]Alignment, 0, segments.Length())
+	var a Alignment
+	for i := 0; i < segments.Length(); i++ {
+		source, target := segments.At(i), converted.At(i)
+		a.Source.End += len(source)
+		a.Target.End += len(target)
+		a.SourceRunes.End += utf8.RuneCountInString(source)
+		a.TargetRunes.End += utf8.RuneCountInString(target)
+		alignments = append(alignments, a)
+
+		a.Source.Start, a.Target.Start = a.Source.End, a.Target.End
+		a.SourceRunes.Start, a.TargetRunes.Start = a.SourceRunes.End, a.TargetRunes.End
+		builder.WriteString(target)
+	}
+	return builder.String(), alignments
+}
+
+// ConvertWithMapping converts the input text and returns the alignment of
+// every segment between the source and the converted text
+func (s *SimpleConverter) ConvertWithMapping(text string) (string, []Alignment) {
+	if s.auto != nil {
+		return s.auto.convertWithMapping(text)
+	}
+	return s.converter.ConvertWithMapping(text)
+}
@@ -0,0 +1,84 @@
+/*
+ * Open Chinese Convert
+ *
+ * Copyright 2010-2014 Carbo Kuo <byvoid@byvoid.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package opencc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yanmingcao/opencc-go/pkg/conversion"
+	"github.com/yanmingcao/opencc-go/pkg/dict"
+	"github.com/yanmingcao/opencc-go/pkg/segmentation"
+)
+
+func TestConvertWithMapping(t *testing.T) {
+	converter := newStreamTestConverter()
+	input := "a简体字b"
+
+	result, alignments := converter.ConvertWithMapping(input)
+	assert.Equal(t, converter.Convert(input), result)
+	require.Len(t, alignments, 3)
+
+	assert.Equal(t, Alignment{
+		Source: Span{0, 1}, Target: Span{0, 1},
+		SourceRunes: Span{0, 1}, TargetRunes: Span{0, 1},
+	}, alignments[0])
+	assert.Equal(t, Alignment{
+		Source: Span{1, 10}, Target: Span{1, 10},
+		SourceRunes: Span{1, 4}, TargetRunes: Span{1, 4},
+	}, alignments[1])
+	assert.Equal(t, Alignment{
+		Source: Span{10, 11}, Target: Span{10, 11},
+		SourceRunes: Span{4, 5}, TargetRunes: Span{4, 5},
+	}, alignments[2])
+
+	for _, a := range alignments {
+		assert.Equal(t, converter.Convert(input[a.Source.Start:a.Source.End]), result[a.Target.Start:a.Target.End])
+	}
+
+	result, alignments = converter.ConvertWithMapping("")
+	assert.Empty(t, result)
+	assert.Empty(t, alignments)
+}
+
+func TestConvertWithMappingChain(t *testing.T) {
+	// Segment lengths change at each step of the chain
+	first := dict.NewLexicon()
+	first.Add(dict.NewStrSingleValueDictEntry("ab", "x"))
+	first.Sort()
+	second := dict.NewLexicon()
+	second.Add(dict.NewStrSingleValueDictEntry("x", "汉字"))
+	second.Sort()
+
+	chain := conversion.NewConversionChain([]*conversion.Conversion{
+		conversion.NewConversion(dict.NewTextDict(first)),
+		conversion.NewConversion(dict.NewTextDict(second)),
+	})
+	converter := NewConverter("test", segmentation.NewMaxMatchSegmentation(dict.NewTextDict(first)), chain)
+
+	result, alignments := converter.ConvertWithMapping("cab")
+	assert.Equal(t, "c汉字", result)
+	require.Len(t, alignments, 2)
+	assert.Equal(t, Span{1, 3}, alignments[1].Source)
+	assert.Equal(t, Span{1, 7}, alignments[1].Target)
+	assert.Equal(t, Span{1, 3}, alignments[1].SourceRunes)
+	assert.Equal(t, Span{1, 3}, alignments[1].TargetRunes)
+}
@@ -21,9 +21,12 @@ package opencc
 import (
 	"bufio"
 	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/yanmingcao/opencc-go/pkg/config"
 	"github.com/yanmingcao/opencc-go/pkg/conversion"
@@ -37,6 +40,7 @@ type Converter struct {
 	name            string
 	segmentation    segmentation.Segmentation
 	conversionChain *conversion.ConversionChain
+	protector       *segmentation.Protector
 }
 
 // NewConverter creates a new Converter
@@ -55,7 +59,7 @@ func (c *Converter) Convert(text string) string {
 	}
 
 	// Step 1: Segment the input text
-	segments := c.segmentation.Segment(text)
+	segments := c.segment(text)
 
 	// Step 2: Apply conversion chain
 	result := c.conversionChain.Convert(segments)
@@ -64,6 +68,14 @@ func (c *Converter) Convert(text string) string {
 	return result.ToString()
 }
 
+// segment segments text, keeping protected spans as single segments
+func (c *Converter) segment(text string) *segmentation.Segments {
+	if c.protector != nil {
+		return c.protector.Segment(c.segmentation, text)
+	}
+	return c.segmentation.Segment(text)
+}
+
 // ConvertToBuffer converts text and writes to the provided buffer
 // Returns the number of bytes written
 func (c *Converter) ConvertToBuffer(input string, buffer []byte) int {
@@ -80,6 +92,17 @@ func (c *Converter) GetSegmentation() segmentation.Segmentation {
 	return c.segmentation
 }
 
+// SetProtector sets the protector whose spans, such as URLs and code, pass
+// through conversion unchanged, or nil to convert everything
+func (c *Converter) SetProtector(p *segmentation.Protector) {
+	c.protector = p
+}
+
+// GetProtector returns the protector, or nil if none is set
+func (c *Converter) GetProtector() *segmentation.Protector {
+	return c.protector
+}
+
 // GetConversionChain returns the conversion chain
 func (c *Converter) GetConversionChain() *conversion.ConversionChain {
 	return c.conversionChain
@@ -90,13 +113,50 @@ func (c *Converter) GetName() string {
 	return c.name
 }
 
+// Close releases the dictionaries of the converter, such as the memory
+// mappings of compiled dictionaries. The converter must not be used
+// afterwards.
+func (c *Converter) Close() error {
+	var errs []error
+	closeDict := func(d dict.Dict) {
+		if closer, ok := d.(io.Closer); ok {
+			errs = append(errs, closer.Close())
+		}
+	}
+	if s, ok := c.segmentation.(interface{ GetDict() dict.Dict }); ok {
+		closeDict(s.GetDict())
+	}
+	for _, conversion := range c.conversionChain.GetConversions() {
+		closeDict(conversion.GetDict())
+	}
+	return errors.Join(errs...)
+}
+
 // SimpleConverter provides a simple high-level API
 type SimpleConverter struct {
 	converter *Converter
+	// base is the converter without user dictionaries, or nil if there
+	// are none
+	base      *Converter
+	userDicts []userDict
+	// auto is set for automatic presets, which pick a converter for every
+	// paragraph
+	auto *autoConverter
 }
 
 // NewSimpleConverter creates a SimpleConverter from a configuration file
 func NewSimpleConverter(configFilename string, searchPaths ...string) (*SimpleConverter, error) {
+	cfg, allPaths, err := loadConfigFile(configFilename, searchPaths)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewSimpleConverterFromConfig(cfg, allPaths...)
+}
+
+// loadConfigFile loads a configuration file and returns it with the search
+// paths for its dictionaries
+func loadConfigFile(configFilename string, searchPaths []string) (*config.Config, []string, error) {
 	// Get the directory of the config file
 	configDir := filepath.Dir(configFilename)
 	if configDir == "" {
@@ -109,16 +169,14 @@ func NewSimpleConverter(configFilename string, searchPaths ...string) (*SimpleCo
 	// Load configuration
 	cfg, err := config.LoadConfig(configFilename)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-
-	return NewSimpleConverterFromConfig(cfg, allPaths...)
+	return cfg, allPaths, nil
 }
 
 // NewSimpleConverterFromConfig creates a SimpleConverter from a Config object
 func NewSimpleConverterFromConfig(cfg *config.Config, searchPaths ...string) (*SimpleConverter, error) {
-	// Build search paths
-	paths := append([]string{"data", "data/dictionary"}, searchPaths...)
+	paths := dictSearchPaths(searchPaths)
 
 	// Create segmentation
 	seg, err := createSegmentation(cfg.Segmentation, paths)
@@ -136,8 +194,17 @@ func NewSimpleConverterFromConfig(cfg *config.Config, searchPaths ...string) (*S
 	return &SimpleConverter{converter: converter}, nil
 }
 
+// dictSearchPaths returns the paths searched for the dictionaries of a
+// configuration
+func dictSearchPaths(searchPaths []string) []string {
+	return append([]string{"data", "data/dictionary"}, searchPaths...)
+}
+
 // Convert converts the input text
 func (s *SimpleConverter) Convert(text string) string {
+	if s.auto != nil {
+		return s.auto.convert(text)
+	}
 	return s.converter.Convert(text)
 }
 
@@ -146,10 +213,10 @@ func (s *SimpleConverter) ConvertCString(input string) string {
 	// Find null terminator
 	for i, ch := range input {
 		if ch == 0 { // null character
-			return s.converter.Convert(input[:i])
+			return s.Convert(input[:i])
 		}
 	}
-	return s.converter.Convert(input)
+	return s.Convert(input)
 }
 
 // ConvertWithLength converts a string with the specified length
@@ -158,14 +225,17 @@ func (s *SimpleConverter) ConvertWithLength(input string, length int) string {
 		return ""
 	}
 	if length >= len(input) {
-		return s.converter.Convert(input)
+		return s.Convert(input)
 	}
-	return s.converter.Convert(input[:length])
+	return s.Convert(input[:length])
 }
 
 // ConvertToBuffer converts text and writes to the provided buffer
 // Returns the number of bytes written
 func (s *SimpleConverter) ConvertToBuffer(input string, buffer []byte) int {
+	if s.auto != nil {
+		return copy(buffer, s.Convert(input))
+	}
 	return s.converter.ConvertToBuffer(input, buffer)
 }
 
@@ -179,24 +249,57 @@ func (s *SimpleConverter) ConvertToBufferWithLength(input string, length int, bu
 	return len(text)
 }
 
-// GetConverter returns the underlying Converter
+// SetProtector sets the protector whose spans pass through conversion
+// unchanged, or nil to convert everything
+func (s *SimpleConverter) SetProtector(p *segmentation.Protector) {
+	if s.auto != nil {
+		s.auto.setProtector(p)
+		return
+	}
+	s.converter.SetProtector(p)
+}
+
+// Close releases the dictionaries of the converter. Converters derived
+// with WithUserDict share them, so only close the last one in use.
+func (s *SimpleConverter) Close() error {
+	if s.auto != nil {
+		var errs []error
+		for _, converter := range s.auto.converters {
+			errs = append(errs, converter.Close())
+		}
+		return errors.Join(errs...)
+	}
+	return s.converter.Close()
+}
+
+// GetConverter returns the underlying Converter, or nil for automatic
+// presets, which have one for every script. Use Transformer to convert
+// those with golang.org/x/text/transform.
 func (s *SimpleConverter) GetConverter() *Converter {
 	return s.converter
 }
 
 // createSegmentation creates a Segmentation from configuration
+// An empty type selects forward maximum matching
 func createSegmentation(cfg *config.SegmentationConfig, searchPaths []string) (segmentation.Segmentation, error) {
-	dict, err := loadDictFromConfig(cfg.Dict, searchPaths)
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	d, err := loadDictFromConfig(cfg.Dict, searchPaths)
 	if err != nil {
 		return nil, err
 	}
-
-	switch cfg.Type {
-	case config.SegmentationTypeMMseg:
-		return segmentation.NewMaxMatchSegmentation(dict), nil
-	default:
-		return segmentation.NewMaxMatchSegmentation(dict), nil
+	seg, err := segmentation.NewSegmentation(&segmentation.SegmentationConfig{
+		Type: segmentation.SegmentationType(cfg.Type),
+		Dict: d,
+	})
+	if err != nil {
+		if closer, ok := d.(io.Closer); ok {
+			closer.Close()
+		}
+		return nil, err
 	}
+	return seg, nil
 }
 
 // createConversionChain creates a ConversionChain from configuration
@@ -209,11 +312,61 @@ func createConversionChain(steps []*config.ConversionStepConfig, searchPaths []s
 			return nil, err
 		}
 		conversions[i] = conversion.NewConversion(d)
+
+		if step.Disambiguation != nil {
+			disambiguator, err := loadDisambiguator(step.Disambiguation, d, searchPaths)
+			if err != nil {
+				return nil, err
+			}
+			conversions[i].SetDisambiguator(disambiguator)
+		}
 	}
 
 	return conversion.NewConversionChain(conversions), nil
 }
 
+// loadDisambiguator learns a disambiguator from the configured scheme and
+// the phrases of the step dictionary
+func loadDisambiguator(cfg *config.DisambiguationConfig, d dict.Dict, searchPaths []string) (*conversion.Disambiguator, error) {
+	var scheme []conversion.SchemeEntry
+	if cfg.Scheme != "" {
+		content, err := loadScheme(cfg.Scheme, searchPaths)
+		if err != nil {
+			return nil, err
+		}
+		scheme, err = conversion.ParseScheme(bytes.NewReader(content))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load scheme %s: %w", cfg.Scheme, err)
+		}
+	}
+	return conversion.NewDisambiguator(scheme, d.GetLexicon()), nil
+}
+
+// loadScheme reads a scheme file, falling back to the embedded scheme of
+// the same name
+func loadScheme(filename string, searchPaths []string) ([]byte, error) {
+	paths := schemeSearchPaths(searchPaths)
+	if path := findFile(filename, paths); path != "" {
+		return os.ReadFile(path)
+	}
+	if filepath.Base(filename) == filename {
+		if content, err := embeddata.GetScheme(filename); err == nil {
+			return content, nil
+		}
+	}
+	return nil, fmt.Errorf("scheme file not found: %s (searched in: %v)", filename, paths)
+}
+
+// schemeSearchPaths returns the paths searched for scheme files: the
+// dictionary search paths and the scheme directories next to them
+func schemeSearchPaths(searchPaths []string) []string {
+	paths := append(append([]string(nil), searchPaths...), "data/scheme")
+	for _, path := range searchPaths {
+		paths = append(paths, filepath.Join(path, "..", "scheme"))
+	}
+	return paths
+}
+
 // loadDictFromConfig loads a dictionary from configuration
 func loadDictFromConfig(cfg *config.DictConfig, searchPaths []string) (dict.Dict, error) {
 	switch cfg.Type {
@@ -229,20 +382,28 @@ func loadDictFromConfig(cfg *config.DictConfig, searchPaths []string) (dict.Dict
 		}
 		return dict.NewDictGroup(dicts), nil
 
-	case "text", "ocd":
-		// TextDict or legacy format
+	case "text":
+		// TextDict
 		return loadTextDict(cfg.File, searchPaths)
 
+	case "ocd":
+		// Legacy Darts double array format
+		return loadDartsDict(cfg.File, searchPaths)
+
 	case "ocd2":
 		// Default Marisa trie format
 		return loadMarisaDict(cfg.File, searchPaths)
 
+	case "bin":
+		// Native compiled format
+		return loadBinaryDict(cfg.File, searchPaths)
+
 	default:
 		return nil, config.ErrUnknownDictType
 	}
 }
 
-// loadTextDict loads a text dictionary
+// loadTextDict loads a text dictionary into a TrieDict
 func loadTextDict(filename string, searchPaths []string) (dict.Dict, error) {
 	path := findFile(filename, searchPaths)
 	if path == "" {
@@ -255,32 +416,67 @@ func loadTextDict(filename string, searchPaths []string) (dict.Dict, error) {
 				}
 
 				lexicon.Sort()
-				return dict.NewTextDict(lexicon), nil
+				return dict.NewSourceDict(dict.NewTrieDict(lexicon), filename, func() (io.ReadCloser, error) {
+					return io.NopCloser(bytes.NewReader(content)), nil
+				}), nil
 			}
 		}
 		return nil, fmt.Errorf("dictionary file not found: %s (searched in: %v)", filename, searchPaths)
 	}
 
-	lexicon, err := dict.ParseLexiconFromFile(path)
-	if err != nil {
-		return nil, err
-	}
-
-	lexicon.Sort()
-	return dict.NewTextDict(lexicon), nil
+	return loadTextDictFile(path)
 }
 
-// loadMarisaDict loads a Marisa trie dictionary (placeholder)
-// In a full implementation, this would use the marisa-trie library
+// loadMarisaDict loads an upstream OpenCC .ocd2 (Marisa trie) dictionary
 func loadMarisaDict(filename string, searchPaths []string) (dict.Dict, error) {
+	return loadCompiledDict(filename, searchPaths, func(path string) (dict.Dict, error) {
+		return dict.NewMarisaDictFromFile(path)
+	})
+}
+
+// loadDartsDict loads a legacy OpenCC .ocd (Darts) dictionary
+func loadDartsDict(filename string, searchPaths []string) (dict.Dict, error) {
+	return loadCompiledDict(filename, searchPaths, func(path string) (dict.Dict, error) {
+		return dict.NewDartsDictFromFile(path)
+	})
+}
+
+// loadBinaryDict memory-maps a dictionary in the native binary format
+func loadBinaryDict(filename string, searchPaths []string) (dict.Dict, error) {
+	return loadCompiledDict(filename, searchPaths, func(path string) (dict.Dict, error) {
+		return dict.NewMmapDictFromFile(path)
+	})
+}
+
+// loadCompiledDict locates a binary dictionary file and loads it with load
+func loadCompiledDict(filename string, searchPaths []string, load func(path string) (dict.Dict, error)) (dict.Dict, error) {
 	path := findFile(filename, searchPaths)
 	if path == "" {
-		return nil, os.ErrNotExist
+		if textName, ok := stockTextDict(filename); ok {
+			return loadTextDict(textName, searchPaths)
+		}
+		return nil, fmt.Errorf("dictionary file not found: %s (searched in: %v)", filename, searchPaths)
 	}
 
-	// For now, fall back to text dict
-	// TODO: Implement proper Marisa trie support
-	return loadTextDict(path, []string{filepath.Dir(path)})
+	d, err := load(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load dictionary %s: %w", path, err)
+	}
+	return dict.NewSourceDict(d, path, nil), nil
+}
+
+// stockTextDict returns the text dictionary that stands in for filename if
+// it names a binary dictionary shipped with upstream OpenCC, such as
+// STPhrases.ocd2. Upstream configs reference those, and they load from the
+// text dictionary of the same name when the binary file is absent. Other
+// binary dictionaries must exist.
+func stockTextDict(filename string) (string, bool) {
+	ext := filepath.Ext(filename)
+	if filepath.Base(filename) != filename || ext != ".ocd2" && ext != ".ocd" {
+		return "", false
+	}
+	name := strings.TrimSuffix(filename, ext)
+	return name + ".txt", embeddata.DictExists(name)
 }
 
 // findFile searches for a file in the given paths
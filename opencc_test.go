@@ -19,6 +19,10 @@
 package opencc
 
 import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -111,6 +115,149 @@ func TestSimpleConverterFromConfig(t *testing.T) {
 	assert.Equal(t, "Test Config", cfg.Name)
 }
 
+func TestSegmentationTypes(t *testing.T) {
+	newConfig := func(segType config.SegmentationType) *config.Config {
+		return &config.Config{
+			Segmentation: &config.SegmentationConfig{
+				Type: segType,
+				Dict: &config.DictConfig{Type: "text", File: "STPhrases.txt"},
+			},
+			ConversionChain: []*config.ConversionStepConfig{
+				{Dict: &config.DictConfig{Type: "text", File: "STCharacters.txt"}},
+			},
+		}
+	}
+
+	for _, segType := range []config.SegmentationType{
+		"",
+		config.SegmentationTypeMMseg,
+		config.SegmentationTypeForward,
+		config.SegmentationTypeBackward,
+		config.SegmentationTypeBidirectional,
+		config.SegmentationTypeDAG,
+	} {
+		converter, err := NewSimpleConverterFromConfig(newConfig(segType))
+		require.NoError(t, err, segType)
+		assert.Equal(t, "漢字", converter.Convert("汉字"), segType)
+	}
+
+	_, err := NewSimpleConverterFromConfig(newConfig("unknown"))
+	assert.ErrorIs(t, err, config.ErrUnknownSegType)
+}
+
+func TestStockDictFallback(t *testing.T) {
+	newConfig := func(dictType, file string) *config.Config {
+		d := &config.DictConfig{Type: dictType, File: file}
+		return &config.Config{
+			Segmentation:    &config.SegmentationConfig{Dict: d},
+			ConversionChain: []*config.ConversionStepConfig{{Dict: d}},
+		}
+	}
+
+	// Binary dictionaries of upstream OpenCC load from the text dictionary
+	for _, file := range []string{"STCharacters.ocd2", "STCharacters.ocd"} {
+		converter, err := NewSimpleConverterFromConfig(newConfig(strings.TrimPrefix(filepath.Ext(file), "."), file))
+		require.NoError(t, err, file)
+		assert.Equal(t, "漢字", converter.Convert("汉字"), file)
+	}
+
+	// Other missing binary dictionaries are errors
+	for _, file := range []string{"STCharacters.bin", "Custom.ocd2", filepath.Join("dictionary", "STCharacters.ocd2")} {
+		_, err := NewSimpleConverterFromConfig(newConfig("ocd2", file))
+		assert.ErrorContains(t, err, "dictionary file not found", file)
+	}
+}
+
+// TestUpstreamConfigs loads the configs of the upstream OpenCC dictionaries
+// in $OPENCC_UPSTREAM_DICTS, such as /usr/share/opencc of an installed
+// package. Each binary dictionary must be found there, so that it is read
+// by its reader rather than replaced by the embedded text dictionary.
+func TestUpstreamConfigs(t *testing.T) {
+	dir := os.Getenv("OPENCC_UPSTREAM_DICTS")
+	configs, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	require.NoError(t, err)
+	if dir == "" || len(configs) == 0 {
+		t.Skip("no upstream configs in $OPENCC_UPSTREAM_DICTS")
+	}
+
+	for _, file := range configs {
+		cfg, searchPaths, err := loadConfigFile(file, nil)
+		require.NoError(t, err, file)
+		var checkDict func(d *config.DictConfig)
+		checkDict = func(d *config.DictConfig) {
+			if d == nil {
+				return
+			}
+			if d.Type == "ocd2" || d.Type == "ocd" {
+				assert.NotEmpty(t, findFile(d.File, searchPaths), "%s: %s", file, d.File)
+			}
+			for _, sub := range d.Dicts {
+				checkDict(sub)
+			}
+		}
+		if cfg.Segmentation != nil {
+			checkDict(cfg.Segmentation.Dict)
+		}
+		for _, step := range cfg.ConversionChain {
+			checkDict(step.Dict)
+		}
+
+		converter, err := NewSimpleConverterFromConfig(cfg, searchPaths...)
+		require.NoError(t, err, file)
+		assert.NotEmpty(t, converter.Convert("汉字"), file)
+		require.NoError(t, converter.Close())
+	}
+}
+
+func TestDisambiguationConfig(t *testing.T) {
+	newConfig := func(disambiguation *config.DisambiguationConfig) *config.Config {
+		return &config.Config{
+			Segmentation: &config.SegmentationConfig{
+				Type: config.SegmentationTypeMMseg,
+				Dict: &config.DictConfig{Type: "text", File: "STPhrases.txt"},
+			},
+			ConversionChain: []*config.ConversionStepConfig{{
+				Dict: &config.DictConfig{Type: "group", Dicts: []*config.DictConfig{
+					{Type: "text", File: "STPhrases.txt"},
+					{Type: "text", File: "STCharacters.txt"},
+				}},
+				Disambiguation: disambiguation,
+			}},
+		}
+	}
+
+	// Disambiguation is opt-in: without it the first value is kept
+	converter, err := NewSimpleConverterFromConfig(newConfig(nil))
+	require.NoError(t, err)
+	assert.Equal(t, "理了個發", converter.Convert("理了个发"))
+
+	converter, err = NewSimpleConverterFromConfig(newConfig(&config.DisambiguationConfig{Scheme: "st_multi.txt"}))
+	require.NoError(t, err)
+	assert.Equal(t, "理了個髮", converter.Convert("理了个发"))
+}
+
+func TestConverterProtector(t *testing.T) {
+	converter := newStreamTestConverter()
+	input := "简体 https://example.com/简体 `汉字` <b title=\"简体\">汉字</b>"
+	assert.Equal(t, "簡體 https://example.com/簡體 `漢字` <b title=\"簡體\">漢字</b>", converter.Convert(input))
+
+	converter.SetProtector(segmentation.NewDefaultProtector())
+	expected := "簡體 https://example.com/简体 `汉字` <b title=\"简体\">漢字</b>"
+	assert.Equal(t, expected, converter.Convert(input))
+
+	output, alignments := converter.ConvertWithMapping(input)
+	assert.Equal(t, expected, output)
+	assert.Equal(t, len(input), alignments[len(alignments)-1].Source.End)
+
+	var out strings.Builder
+	require.NoError(t, converter.ConvertStream(context.Background(), strings.NewReader(input), &out))
+	assert.Equal(t, expected, out.String())
+
+	converter.SetProtector(nil)
+	assert.Nil(t, converter.GetProtector())
+	assert.Equal(t, "簡體 https://example.com/簡體 `漢字` <b title=\"簡體\">漢字</b>", converter.Convert(input))
+}
+
 func TestSegmentation(t *testing.T) {
 	// Create dictionary
 	lexicon := dict.NewLexicon()
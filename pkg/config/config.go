@@ -21,6 +21,7 @@ package config
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 )
@@ -50,7 +51,11 @@ type SegmentationConfig struct {
 type SegmentationType string
 
 const (
-	SegmentationTypeMMseg SegmentationType = "mmseg"
+	SegmentationTypeMMseg         SegmentationType = "mmseg" // forward maximum matching
+	SegmentationTypeForward       SegmentationType = "fmm"
+	SegmentationTypeBackward      SegmentationType = "bmm"
+	SegmentationTypeBidirectional SegmentationType = "bimm"
+	SegmentationTypeDAG           SegmentationType = "dag"
 )
 
 // DictConfig represents dictionary configuration
@@ -62,7 +67,15 @@ type DictConfig struct {
 
 // ConversionStepConfig represents a single conversion step configuration
 type ConversionStepConfig struct {
-	Dict *DictConfig `json:"dict"`
+	Dict           *DictConfig           `json:"dict"`
+	Disambiguation *DisambiguationConfig `json:"disambiguation,omitempty"`
+}
+
+// DisambiguationConfig enables context-aware selection among the values of
+// one-to-many characters in a conversion step
+type DisambiguationConfig struct {
+	// Scheme is a scheme file such as st_multi.txt with example phrases
+	Scheme string `json:"scheme,omitempty"`
 }
 
 // LoadConfig loads configuration from a JSON file
@@ -139,5 +152,18 @@ func (c *Config) Validate() error {
 		return ErrMissingField
 	}
 
-	return nil
+	return c.Segmentation.Validate()
+}
+
+// Validate checks the segmentation type
+// An empty type selects forward maximum matching; an unknown type is an
+// error wrapping ErrUnknownSegType
+func (c *SegmentationConfig) Validate() error {
+	switch c.Type {
+	case "", SegmentationTypeMMseg, SegmentationTypeForward, SegmentationTypeBackward,
+		SegmentationTypeBidirectional, SegmentationTypeDAG:
+		return nil
+	default:
+		return fmt.Errorf("%w: %s", ErrUnknownSegType, c.Type)
+	}
 }
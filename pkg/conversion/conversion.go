@@ -25,7 +25,8 @@ import (
 
 // Conversion performs a single conversion step using a dictionary
 type Conversion struct {
-	dict dict.Dict
+	dict          dict.Dict
+	disambiguator *Disambiguator
 }
 
 // NewConversion creates a new Conversion with the given dictionary
@@ -48,6 +49,20 @@ func (c *Conversion) Convert(phrase string) string {
 	return phrase
 }
 
+// Candidates returns all values of the entry matching phrase, default
+// first, or the phrase itself if nothing matches
+func (c *Conversion) Candidates(phrase string) []string {
+	if len(phrase) == 0 {
+		return []string{phrase}
+	}
+
+	entry := c.dict.Match(phrase)
+	if entry == nil || entry.NumValues() == 0 {
+		return []string{phrase}
+	}
+	return entry.Values()
+}
+
 // Convert converts segmented text
 func (c *Conversion) ConvertSegments(segments *segmentation.Segments) *segmentation.Segments {
 	result := segmentation.NewSegments()
@@ -55,18 +70,79 @@ func (c *Conversion) ConvertSegments(segments *segmentation.Segments) *segmentat
 	iterator := segments.Iterator()
 	for iterator.Next() {
 		segment := iterator.Value()
+		if segments.IsProtected(iterator.Position()) {
+			result.AddProtected(segment)
+			continue
+		}
 		converted := c.Convert(segment)
 		result.AddManaged(converted)
 	}
 
+	if c.disambiguator != nil {
+		return c.disambiguator.Disambiguate(segments, result, c.dict)
+	}
 	return result
 }
 
+// Trace records how a conversion step converted one segment
+type Trace struct {
+	Input  string
+	Output string
+	// Entry is the matched dictionary entry, or nil if none matched
+	Entry dict.DictEntry
+	// Source is where the matched entry is defined
+	Source dict.Provenance
+}
+
+// ConvertSegmentsWithTrace converts segmented text like ConvertSegments
+// and records a Trace for every segment
+func (c *Conversion) ConvertSegmentsWithTrace(segments *segmentation.Segments) (*segmentation.Segments, []Trace) {
+	result := segmentation.NewSegments()
+	traces := make([]Trace, 0, segments.Length())
+
+	iterator := segments.Iterator()
+	for iterator.Next() {
+		trace := Trace{Input: iterator.Value(), Output: iterator.Value()}
+		if segments.IsProtected(iterator.Position()) {
+			result.AddProtected(trace.Output)
+			traces = append(traces, trace)
+			continue
+		}
+		if len(trace.Input) > 0 {
+			trace.Entry, trace.Source = dict.MatchWithProvenance(c.dict, trace.Input)
+			if trace.Entry != nil {
+				trace.Output = trace.Entry.GetDefault()
+			}
+		}
+		result.AddManaged(trace.Output)
+		traces = append(traces, trace)
+	}
+
+	if c.disambiguator != nil {
+		result = c.disambiguator.Disambiguate(segments, result, c.dict)
+		for i := range traces {
+			traces[i].Output = result.At(i)
+		}
+	}
+	return result, traces
+}
+
 // GetDict returns the dictionary used for conversion
 func (c *Conversion) GetDict() dict.Dict {
 	return c.dict
 }
 
+// SetDisambiguator sets the disambiguator used to choose among the values
+// of one-to-many characters, or nil to always take the default value
+func (c *Conversion) SetDisambiguator(d *Disambiguator) {
+	c.disambiguator = d
+}
+
+// GetDisambiguator returns the disambiguator, or nil if none is set
+func (c *Conversion) GetDisambiguator() *Disambiguator {
+	return c.disambiguator
+}
+
 // ConversionChain represents a chain of conversions applied in sequence
 type ConversionChain struct {
 	conversions []*Conversion
@@ -80,6 +156,7 @@ func NewConversionChain(conversions []*Conversion) *ConversionChain {
 }
 
 // Convert applies all conversions in the chain
+// Each conversion maps segment i of its input to segment i of its output
 func (c *ConversionChain) Convert(segments *segmentation.Segments) *segmentation.Segments {
 	result := segments
 
@@ -90,6 +167,21 @@ func (c *ConversionChain) Convert(segments *segmentation.Segments) *segmentation
 	return result
 }
 
+// ConvertWithTrace applies all conversions in the chain like Convert and
+// returns the traces of every step, indexed by step and then by segment
+func (c *ConversionChain) ConvertWithTrace(segments *segmentation.Segments) (*segmentation.Segments, [][]Trace) {
+	result := segments
+	traces := make([][]Trace, 0, len(c.conversions))
+
+	for _, conversion := range c.conversions {
+		var stepTraces []Trace
+		result, stepTraces = conversion.ConvertSegmentsWithTrace(result)
+		traces = append(traces, stepTraces)
+	}
+
+	return result, traces
+}
+
 // GetConversions returns the list of conversions
 func (c *ConversionChain) GetConversions() []*Conversion {
 	return c.conversions
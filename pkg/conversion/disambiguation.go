@@ -0,0 +1,207 @@
+/*
+ * Open Chinese Convert
+ *
+ * Copyright 2010-2014 Carbo Kuo <byvoid@byvoid.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package conversion
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/yanmingcao/opencc-go/pkg/dict"
+	"github.com/yanmingcao/opencc-go/pkg/segmentation"
+)
+
+// SchemeEntry is one line of a one-to-many scheme such as st_multi.txt
+type SchemeEntry struct {
+	Char        string
+	Candidates  []string
+	Description string
+	Examples    []string
+}
+
+// ParseScheme parses a tab-separated scheme file: the character, its
+// space-separated candidates, a description and space-separated example
+// phrases written with the candidates
+func ParseScheme(reader io.Reader) ([]SchemeEntry, error) {
+	var entries []SchemeEntry
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		if len(fields) < 2 {
+			continue
+		}
+		entry := SchemeEntry{Char: fields[0], Candidates: strings.Fields(fields[1])}
+		if len(fields) > 2 {
+			entry.Description = fields[2]
+		}
+		if len(fields) > 3 {
+			entry.Examples = strings.Fields(fields[3])
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// candidateContext counts the characters seen next to a candidate
+type candidateContext struct {
+	left  map[rune]int
+	right map[rune]int
+}
+
+// Disambiguator chooses among the values of one-to-many characters using
+// the characters around them. Which neighbors suit each candidate is learned
+// from the example phrases of a scheme and from the phrases of a dictionary.
+type Disambiguator struct {
+	contexts map[string]*candidateContext
+}
+
+// NewDisambiguator learns contexts from scheme examples and from the
+// phrases of lexicon whose values map a character one-to-one
+func NewDisambiguator(scheme []SchemeEntry, lexicon *dict.Lexicon) *Disambiguator {
+	d := &Disambiguator{contexts: make(map[string]*candidateContext)}
+
+	// Characters whose conversion is ambiguous, with their candidates
+	ambiguous := make(map[rune]map[rune]bool)
+	addCandidates := func(char string, candidates []string) {
+		r, size := utf8.DecodeRuneInString(char)
+		if size != len(char) || len(candidates) < 2 {
+			return
+		}
+		if ambiguous[r] == nil {
+			ambiguous[r] = make(map[rune]bool)
+		}
+		for _, candidate := range candidates {
+			if c, size := utf8.DecodeRuneInString(candidate); size == len(candidate) {
+				ambiguous[r][c] = true
+			}
+		}
+	}
+	for _, entry := range scheme {
+		addCandidates(entry.Char, entry.Candidates)
+	}
+	if lexicon != nil {
+		for _, entry := range lexicon.Entries() {
+			addCandidates(entry.Key(), entry.Values())
+		}
+	}
+
+	for _, entry := range scheme {
+		r, _ := utf8.DecodeRuneInString(entry.Char)
+		for _, example := range entry.Examples {
+			runes := []rune(example)
+			for i, c := range runes {
+				if ambiguous[r][c] {
+					d.learn(c, runes, i)
+				}
+			}
+		}
+	}
+
+	if lexicon != nil {
+		for _, entry := range lexicon.Entries() {
+			if entry.NumValues() == 0 || utf8.RuneCountInString(entry.Key()) < 2 {
+				continue
+			}
+			key := []rune(entry.Key())
+			value := []rune(entry.GetDefault())
+			if len(key) != len(value) {
+				continue
+			}
+			for i, r := range key {
+				if ambiguous[r][value[i]] {
+					d.learn(value[i], value, i)
+				}
+			}
+		}
+	}
+
+	return d
+}
+
+// learn records the neighbors of the candidate at runes[i]
+func (d *Disambiguator) learn(candidate rune, runes []rune, i int) {
+	ctx := d.contexts[string(candidate)]
+	if ctx == nil {
+		ctx = &candidateContext{left: make(map[rune]int), right: make(map[rune]int)}
+		d.contexts[string(candidate)] = ctx
+	}
+	if i > 0 {
+		ctx.left[runes[i-1]]++
+	}
+	if i+1 < len(runes) {
+		ctx.right[runes[i+1]]++
+	}
+}
+
+// Choose returns the candidate that best fits between left and right, the
+// converted text before and after it. The first candidate is the default
+// and is kept unless another one fits strictly better.
+func (d *Disambiguator) Choose(candidates []string, left, right string) string {
+	if len(candidates) == 0 {
+		return ""
+	}
+
+	prev, _ := utf8.DecodeLastRuneInString(left)
+	next, _ := utf8.DecodeRuneInString(right)
+	best, bestScore := candidates[0], 0
+	for _, candidate := range candidates {
+		ctx := d.contexts[candidate]
+		if ctx == nil {
+			continue
+		}
+		score := 0
+		if len(left) > 0 {
+			score += ctx.left[prev]
+		}
+		if len(right) > 0 {
+			score += ctx.right[next]
+		}
+		if score > bestScore {
+			best, bestScore = candidate, score
+		}
+	}
+	return best
+}
+
+// Disambiguate revisits the single-character segments of converted that
+// have several values in lookup and picks the one that fits their neighbors.
+// source holds the segments before conversion.
+func (d *Disambiguator) Disambiguate(source, converted *segmentation.Segments, lookup dict.Dict) *segmentation.Segments {
+	result := segmentation.NewSegments()
+	for i := 0; i < converted.Length(); i++ {
+		output := converted.At(i)
+		if converted.IsProtected(i) {
+			result.AddProtected(output)
+			continue
+		}
+		segment := source.At(i)
+		if utf8.RuneCountInString(segment) == 1 {
+			if entry := lookup.Match(segment); entry != nil && entry.NumValues() > 1 {
+				output = d.Choose(entry.Values(), result.At(i-1), converted.At(i+1))
+			}
+		}
+		result.AddManaged(output)
+	}
+	return result
+}
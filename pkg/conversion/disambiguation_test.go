@@ -0,0 +1,76 @@
+/*
+ * Open Chinese Convert
+ *
+ * Copyright 2010-2014 Carbo Kuo <byvoid@byvoid.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package conversion
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yanmingcao/opencc-go/pkg/dict"
+	"github.com/yanmingcao/opencc-go/pkg/segmentation"
+)
+
+const testScheme = "发\t發 髮\t與毛髮有關用「髮」，其餘意義爲「發」。\t發財 發送 頭髮 令人髮指\n" +
+	"只\t只 隻\t「隻」用作量詞，「只」爲副詞「僅」。\n"
+
+func TestParseScheme(t *testing.T) {
+	scheme, err := ParseScheme(strings.NewReader(testScheme))
+	require.NoError(t, err)
+	require.Len(t, scheme, 2)
+
+	assert.Equal(t, "发", scheme[0].Char)
+	assert.Equal(t, []string{"發", "髮"}, scheme[0].Candidates)
+	assert.Equal(t, []string{"發財", "發送", "頭髮", "令人髮指"}, scheme[0].Examples)
+	assert.Equal(t, []string{"只", "隻"}, scheme[1].Candidates)
+	assert.Empty(t, scheme[1].Examples)
+}
+
+func TestDisambiguator(t *testing.T) {
+	scheme, err := ParseScheme(strings.NewReader(testScheme))
+	require.NoError(t, err)
+
+	lexicon := dict.NewLexicon()
+	lexicon.Add(dict.NewStrMultiValueDictEntry("发", []string{"發", "髮"}))
+	lexicon.Add(dict.NewStrSingleValueDictEntry("短发", "短髮"))
+	lexicon.Add(dict.NewStrSingleValueDictEntry("只", "隻"))
+	lexicon.Add(dict.NewStrSingleValueDictEntry("短", "短"))
+	lexicon.Add(dict.NewStrSingleValueDictEntry("头", "頭"))
+	lexicon.Sort()
+	d := dict.NewTextDict(lexicon)
+
+	disambiguator := NewDisambiguator(scheme, lexicon)
+	assert.Equal(t, "髮", disambiguator.Choose([]string{"發", "髮"}, "頭", ""))
+	assert.Equal(t, "髮", disambiguator.Choose([]string{"發", "髮"}, "很短", ""))
+	assert.Equal(t, "發", disambiguator.Choose([]string{"發", "髮"}, "", "財"))
+	assert.Equal(t, "發", disambiguator.Choose([]string{"發", "髮"}, "", ""))
+
+	conversion := NewConversion(d)
+	segments := segmentation.NewSegmentsFromStrings([]string{"头", "发", "和", "发", "财"})
+	assert.Equal(t, "頭發和發财", conversion.ConvertSegments(segments).ToString())
+
+	conversion.SetDisambiguator(disambiguator)
+	assert.Equal(t, "頭髮和發财", conversion.ConvertSegments(segments).ToString())
+
+	result, traces := conversion.ConvertSegmentsWithTrace(segments)
+	assert.Equal(t, "頭髮和發财", result.ToString())
+	assert.Equal(t, "髮", traces[1].Output)
+}
@@ -0,0 +1,444 @@
+/*
+ * Open Chinese Convert
+ *
+ * Copyright 2010-2020 Carbo Kuo <byvoid@byvoid.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dict
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// BinaryDictVersion is the version of the native binary format
+const BinaryDictVersion = 2
+
+// binaryDictHeaderSize is the size of the fixed header:
+//
+//	header [12]byte   BinaryDictHeader
+//	version u32       BinaryDictVersion
+//	checksum u32      CRC-32 (IEEE) of everything after the header
+//	numEntries u32
+//	numStrings u32    keys plus values
+//	maxKeyLength u32
+//	poolSize u32
+//	flags u32         binaryDictHasWeights
+//	totalWeight f64   see TotalWeight
+//
+// It is followed by entryStart [numEntries+1]u32 (index of each entry's key
+// in the string table, its values follow the key), stringOffsets
+// [numStrings+1]u32 (byte offsets into the pool), if the flags have
+// binaryDictHasWeights, weights [numEntries]f64 (NaN for entries without a
+// weight), and the string pool. Entries are sorted by key and all numbers
+// are little-endian.
+const binaryDictHeaderSize = 48
+
+// binaryDictHasWeights flags a dictionary that stores entry weights
+const binaryDictHasWeights = 1
+
+// binaryDictLimit is the largest string count and pool size the 32-bit
+// tables of the format can address
+var binaryDictLimit uint64 = math.MaxUint32
+
+// CompiledDict is a dictionary in the compact native binary format. Lookups
+// are answered directly from the encoded bytes; entries are only
+// materialized when they are returned.
+type CompiledDict struct {
+	data          []byte
+	numEntries    int
+	numStrings    int
+	maxLength     int
+	entryStart    []byte
+	stringOffsets []byte
+	weights       []byte
+	pool          []byte
+}
+
+// NewCompiledDict encodes a lexicon into a CompiledDict
+// The lexicon must be sorted. It returns ErrDictTooLarge if the lexicon
+// does not fit the 32-bit tables of the format.
+func NewCompiledDict(lexicon *Lexicon) (*CompiledDict, error) {
+	data, err := encodeBinaryDict(lexicon)
+	if err != nil {
+		return nil, err
+	}
+	return newCompiledDict(data, false)
+}
+
+// NewCompiledDictFromFile loads a CompiledDict from a file
+func NewCompiledDictFromFile(filename string) (*CompiledDict, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return NewCompiledDictFromBytes(data)
+}
+
+// NewCompiledDictFromReader loads a CompiledDict from a reader
+func NewCompiledDictFromReader(reader io.Reader) (*CompiledDict, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	return NewCompiledDictFromBytes(data)
+}
+
+// NewCompiledDictFromBytes verifies data and returns a CompiledDict backed by
+// it. The slice is used without copying and must not be modified.
+func NewCompiledDictFromBytes(data []byte) (*CompiledDict, error) {
+	return newCompiledDict(data, true)
+}
+
+// newCompiledDict returns a CompiledDict backed by data after checking its
+// header and size. Checking the header does not touch the rest of data, so
+// a mapped file is paged in lazily; with verify set, the checksum and the
+// tables are checked as well, which reads all of it.
+func newCompiledDict(data []byte, verify bool) (*CompiledDict, error) {
+	if len(data) < binaryDictHeaderSize || !bytes.HasPrefix(data, BinaryDictHeader) {
+		return nil, ErrInvalidHeader
+	}
+	le := binary.LittleEndian
+	if version := le.Uint32(data[12:]); version != BinaryDictVersion {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidVersion, version)
+	}
+	numEntries := uint64(le.Uint32(data[20:]))
+	numStrings := uint64(le.Uint32(data[24:]))
+	poolSize := uint64(le.Uint32(data[32:]))
+	flags := le.Uint32(data[36:])
+	if flags&^binaryDictHasWeights != 0 {
+		return nil, fmt.Errorf("%w: unknown flags %#x", ErrInvalidFormat, flags)
+	}
+	weightsSize := uint64(0)
+	if flags&binaryDictHasWeights != 0 {
+		weightsSize = numEntries * 8
+	}
+	if binaryDictHeaderSize+(numEntries+1)*4+(numStrings+1)*4+weightsSize+poolSize != uint64(len(data)) {
+		return nil, fmt.Errorf("%w: size mismatch", ErrInvalidFormat)
+	}
+
+	d := &CompiledDict{
+		data:       data,
+		numEntries: int(numEntries),
+		numStrings: int(numStrings),
+		maxLength:  int(le.Uint32(data[28:])),
+	}
+	pos := binaryDictHeaderSize
+	d.entryStart = data[pos : pos+int(numEntries+1)*4]
+	pos += len(d.entryStart)
+	d.stringOffsets = data[pos : pos+int(numStrings+1)*4]
+	pos += len(d.stringOffsets)
+	d.weights = data[pos : pos+int(weightsSize)]
+	pos += len(d.weights)
+	d.pool = data[pos:]
+
+	if verify {
+		if err := d.Verify(); err != nil {
+			return nil, err
+		}
+	}
+	return d, nil
+}
+
+// Verify checks the checksum and the tables of the dictionary. It reads all
+// of the data. Lookups in a dictionary that fails verification do not panic
+// but may return wrong entries.
+func (d *CompiledDict) Verify() error {
+	if crc32.ChecksumIEEE(d.data[binaryDictHeaderSize:]) != binary.LittleEndian.Uint32(d.data[16:]) {
+		return fmt.Errorf("%w: checksum mismatch", ErrInvalidFormat)
+	}
+	for i := 0; i < d.numStrings; i++ {
+		if d.stringOffset(i) > d.stringOffset(i+1) {
+			return fmt.Errorf("%w: string offsets not monotonic", ErrInvalidFormat)
+		}
+	}
+	if d.stringOffset(d.numStrings) != len(d.pool) {
+		return fmt.Errorf("%w: string pool size mismatch", ErrInvalidFormat)
+	}
+	for i := 0; i < d.numEntries; i++ {
+		if d.entryIndex(i) >= d.entryIndex(i+1) {
+			return fmt.Errorf("%w: entry without key", ErrInvalidFormat)
+		}
+	}
+	if d.entryIndex(d.numEntries) != d.numStrings {
+		return fmt.Errorf("%w: entry table size mismatch", ErrInvalidFormat)
+	}
+	return nil
+}
+
+// encodeBinaryDict serializes a sorted lexicon into the native binary format
+func encodeBinaryDict(lexicon *Lexicon) ([]byte, error) {
+	var pool []byte
+	var entryStart, stringOffsets []uint32
+	var weights []float64
+	hasWeights := false
+	totalWeight := 0.0
+	maxLength := 0
+	addString := func(s string) error {
+		// The string count is also stored as the end of the entry table
+		if uint64(len(stringOffsets))+1 > binaryDictLimit {
+			return fmt.Errorf("%w: more than %d strings", ErrDictTooLarge, binaryDictLimit)
+		}
+		if uint64(len(pool))+uint64(len(s)) > binaryDictLimit {
+			return fmt.Errorf("%w: strings exceed %d bytes", ErrDictTooLarge, binaryDictLimit)
+		}
+		stringOffsets = append(stringOffsets, uint32(len(pool)))
+		pool = append(pool, s...)
+		return nil
+	}
+	for _, entry := range lexicon.entries {
+		entryStart = append(entryStart, uint32(len(stringOffsets)))
+		if err := addString(entry.Key()); err != nil {
+			return nil, err
+		}
+		for _, value := range entry.Values() {
+			if err := addString(value); err != nil {
+				return nil, err
+			}
+		}
+		if entry.KeyLength() > maxLength {
+			maxLength = entry.KeyLength()
+		}
+		weight, ok := EntryWeight(entry)
+		if !ok {
+			weight = math.NaN()
+		}
+		hasWeights = hasWeights || ok
+		weights = append(weights, weight)
+		totalWeight += WeightOrDefault(entry)
+	}
+	entryStart = append(entryStart, uint32(len(stringOffsets)))
+	numStrings := len(stringOffsets)
+	stringOffsets = append(stringOffsets, uint32(len(pool)))
+
+	if !hasWeights {
+		weights = nil
+	}
+
+	le := binary.LittleEndian
+	data := make([]byte, binaryDictHeaderSize, binaryDictHeaderSize+4*(len(entryStart)+len(stringOffsets))+8*len(weights)+len(pool))
+	copy(data, BinaryDictHeader)
+	le.PutUint32(data[12:], BinaryDictVersion)
+	le.PutUint32(data[20:], uint32(lexicon.Len()))
+	le.PutUint32(data[24:], uint32(numStrings))
+	le.PutUint32(data[28:], uint32(maxLength))
+	le.PutUint32(data[32:], uint32(len(pool)))
+	if hasWeights {
+		le.PutUint32(data[36:], binaryDictHasWeights)
+	}
+	le.PutUint64(data[40:], math.Float64bits(totalWeight))
+	for _, v := range entryStart {
+		data = le.AppendUint32(data, v)
+	}
+	for _, v := range stringOffsets {
+		data = le.AppendUint32(data, v)
+	}
+	for _, v := range weights {
+		data = le.AppendUint64(data, math.Float64bits(v))
+	}
+	data = append(data, pool...)
+	le.PutUint32(data[16:], crc32.ChecksumIEEE(data[binaryDictHeaderSize:]))
+	return data, nil
+}
+
+// entryIndex returns the string index of the key of entry i
+func (d *CompiledDict) entryIndex(i int) int {
+	return int(binary.LittleEndian.Uint32(d.entryStart[i*4:]))
+}
+
+// stringOffset returns the pool offset of string i
+func (d *CompiledDict) stringOffset(i int) int {
+	return int(binary.LittleEndian.Uint32(d.stringOffsets[i*4:]))
+}
+
+// stringBytes returns string i without copying. Indexes and offsets out of
+// range, which only unverified data can hold, give an empty string.
+func (d *CompiledDict) stringBytes(i int) []byte {
+	if i >= d.numStrings {
+		return nil
+	}
+	start, end := d.stringOffset(i), d.stringOffset(i+1)
+	if start > end || end > len(d.pool) {
+		return nil
+	}
+	return d.pool[start:end]
+}
+
+// keyBytes returns the key of entry i without copying
+func (d *CompiledDict) keyBytes(i int) []byte {
+	return d.stringBytes(d.entryIndex(i))
+}
+
+// entry materializes entry i
+func (d *CompiledDict) entry(i int) DictEntry {
+	first := d.entryIndex(i)
+	last := max(min(d.entryIndex(i+1), d.numStrings), first+1)
+	values := make([]string, 0, last-first-1)
+	for s := first + 1; s < last; s++ {
+		values = append(values, string(d.stringBytes(s)))
+	}
+	entry := EntryFactory.NewMulti(string(d.stringBytes(first)), values)
+	if len(d.weights) > 0 {
+		if weight := math.Float64frombits(binary.LittleEndian.Uint64(d.weights[i*8:])); !math.IsNaN(weight) {
+			return NewWeightedDictEntry(entry, weight)
+		}
+	}
+	return entry
+}
+
+// compareKey compares b with s like strings.Compare, without allocating
+func compareKey(b []byte, s string) int {
+	n := min(len(b), len(s))
+	for i := 0; i < n; i++ {
+		if b[i] != s[i] {
+			if b[i] < s[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case len(b) < len(s):
+		return -1
+	case len(b) > len(s):
+		return 1
+	}
+	return 0
+}
+
+// prefixMatches calls fn with the index and key length of every entry whose
+// key is a prefix of word, shortest first. The candidate range is narrowed
+// with each additional byte, so the walk stops as soon as no key can match.
+func (d *CompiledDict) prefixMatches(word string, fn func(index, length int)) {
+	lo, hi := 0, d.numEntries
+	maxLen := min(len(word), d.maxLength)
+	for l := 1; l <= maxLen && lo < hi; l++ {
+		prefix := word[:l]
+		lo += sort.Search(hi-lo, func(i int) bool {
+			return compareKey(d.keyBytes(lo+i), prefix) >= 0
+		})
+		hi = lo + sort.Search(hi-lo, func(i int) bool {
+			key := d.keyBytes(lo + i)
+			return len(key) < l || compareKey(key[:l], prefix) != 0
+		})
+		if lo < hi && len(d.keyBytes(lo)) == l {
+			fn(lo, l)
+		}
+	}
+}
+
+// Match performs exact matching
+func (d *CompiledDict) Match(word string) DictEntry {
+	if len(word) > d.maxLength {
+		return nil
+	}
+	idx := sort.Search(d.numEntries, func(i int) bool {
+		return compareKey(d.keyBytes(i), word) >= 0
+	})
+	if idx < d.numEntries && compareKey(d.keyBytes(idx), word) == 0 {
+		return d.entry(idx)
+	}
+	return nil
+}
+
+// MatchPrefix finds the longest matching prefix
+func (d *CompiledDict) MatchPrefix(word string) DictEntry {
+	longest := -1
+	d.prefixMatches(word, func(index, length int) {
+		longest = index
+	})
+	if longest < 0 {
+		return nil
+	}
+	return d.entry(longest)
+}
+
+// MatchAllPrefixes finds all matching prefixes, sorted by length (descending)
+func (d *CompiledDict) MatchAllPrefixes(word string) []DictEntry {
+	var results []DictEntry
+	d.prefixMatches(word, func(index, length int) {
+		results = append(results, d.entry(index))
+	})
+	for i, j := 0, len(results)-1; i < j; i, j = i+1, j-1 {
+		results[i], results[j] = results[j], results[i]
+	}
+	return results
+}
+
+// KeyMaxLength returns the maximum key length
+func (d *CompiledDict) KeyMaxLength() int {
+	return d.maxLength
+}
+
+// TotalWeight returns the sum of the entry weights stored in the header
+func (d *CompiledDict) TotalWeight() float64 {
+	return math.Float64frombits(binary.LittleEndian.Uint64(d.data[40:]))
+}
+
+// Len returns the number of entries
+func (d *CompiledDict) Len() int {
+	return d.numEntries
+}
+
+// GetLexicon materializes all entries into a lexicon
+func (d *CompiledDict) GetLexicon() *Lexicon {
+	entries := make([]DictEntry, d.numEntries)
+	for i := range entries {
+		entries[i] = d.entry(i)
+	}
+	return &Lexicon{entries: entries}
+}
+
+// SerializeToFile serializes the dictionary to a file. The file is written
+// next to filename and renamed over it, so processes that have the old file
+// mapped keep reading the old contents instead of faulting on a truncated
+// mapping. An existing file keeps its permissions.
+func (d *CompiledDict) SerializeToFile(filename string) error {
+	perm := fs.FileMode(0644)
+	if info, err := os.Stat(filename); err == nil {
+		perm = info.Mode().Perm()
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(filename), "."+filepath.Base(filename)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := d.SerializeToWriter(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filename)
+}
+
+// SerializeToWriter serializes the dictionary to a writer
+func (d *CompiledDict) SerializeToWriter(writer io.Writer) error {
+	_, err := writer.Write(d.data)
+	return err
+}
@@ -0,0 +1,187 @@
+/*
+ * Open Chinese Convert
+ *
+ * Copyright 2010-2020 Carbo Kuo <byvoid@byvoid.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dict
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLexicon() *Lexicon {
+	lexicon := NewLexicon()
+	lexicon.Add(NewStrSingleValueDictEntry("a", "A"))
+	lexicon.Add(NewStrSingleValueDictEntry("ab", "AB"))
+	lexicon.Add(NewStrSingleValueDictEntry("abc", "ABC"))
+	lexicon.Add(NewStrSingleValueDictEntry("b", "B"))
+	lexicon.Add(NewStrSingleValueDictEntry("简体", "簡體"))
+	lexicon.Add(NewStrMultiValueDictEntry("发", []string{"發", "髮"}))
+	lexicon.Add(NewNoValueDictEntry("无值"))
+	lexicon.Sort()
+	return lexicon
+}
+
+func mustCompile(t *testing.T, lexicon *Lexicon) *CompiledDict {
+	d, err := NewCompiledDict(lexicon)
+	require.NoError(t, err)
+	return d
+}
+
+func TestCompiledDict(t *testing.T) {
+	lexicon := newTestLexicon()
+	d := mustCompile(t, lexicon)
+
+	assert.Equal(t, lexicon.Len(), d.Len())
+	assert.Equal(t, 6, d.KeyMaxLength())
+
+	entry := d.Match("发")
+	require.NotNil(t, entry)
+	assert.Equal(t, []string{"發", "髮"}, entry.Values())
+
+	entry = d.Match("无值")
+	require.NotNil(t, entry)
+	assert.Equal(t, 0, entry.NumValues())
+
+	assert.Nil(t, d.Match("abcd"))
+	assert.Nil(t, d.Match(""))
+
+	entry = d.MatchPrefix("简体字")
+	require.NotNil(t, entry)
+	assert.Equal(t, "簡體", entry.GetDefault())
+
+	all := d.MatchAllPrefixes("abd")
+	require.Len(t, all, 2)
+	assert.Equal(t, "ab", all[0].Key())
+	assert.Equal(t, "a", all[1].Key())
+
+	restored := d.GetLexicon()
+	require.Equal(t, lexicon.Len(), restored.Len())
+	for i := 0; i < lexicon.Len(); i++ {
+		assert.Equal(t, lexicon.At(i).ToString(), restored.At(i).ToString())
+	}
+}
+
+func TestBinaryDictSerialization(t *testing.T) {
+	d := mustCompile(t, newTestLexicon())
+
+	filename := filepath.Join(t.TempDir(), "test.bin")
+	require.NoError(t, d.SerializeToFile(filename))
+
+	loaded, err := NewCompiledDictFromFile(filename)
+	require.NoError(t, err)
+	entry := loaded.Match("简体")
+	require.NotNil(t, entry)
+	assert.Equal(t, "簡體", entry.GetDefault())
+
+	var buf bytes.Buffer
+	require.NoError(t, d.SerializeToWriter(&buf))
+	data := buf.Bytes()
+
+	corrupted := append([]byte(nil), data...)
+	corrupted[len(corrupted)-1] ^= 0xFF
+	_, err = NewCompiledDictFromBytes(corrupted)
+	assert.ErrorIs(t, err, ErrInvalidFormat)
+
+	versioned := append([]byte(nil), data...)
+	versioned[12] = 99
+	_, err = NewCompiledDictFromBytes(versioned)
+	assert.ErrorIs(t, err, ErrInvalidVersion)
+
+	_, err = NewCompiledDictFromBytes(data[:20])
+	assert.ErrorIs(t, err, ErrInvalidHeader)
+}
+
+func TestCompiledDictWeights(t *testing.T) {
+	lexicon := NewLexicon()
+	lexicon.Add(NewWeightedDictEntry(NewStrSingleValueDictEntry("分子", "分子"), 80))
+	lexicon.Add(NewStrSingleValueDictEntry("成", "成"))
+	lexicon.Add(NewWeightedDictEntry(NewStrSingleValueDictEntry("成分", "成分"), 0))
+	lexicon.Sort()
+	d := mustCompile(t, lexicon)
+
+	weight, ok := EntryWeight(d.Match("分子"))
+	assert.True(t, ok)
+	assert.Equal(t, 80.0, weight)
+	_, ok = EntryWeight(d.Match("成"))
+	assert.False(t, ok)
+	weight, ok = EntryWeight(d.Match("成分"))
+	assert.True(t, ok)
+	assert.Equal(t, 0.0, weight)
+
+	assert.Equal(t, 82.0, d.TotalWeight())
+	assert.Equal(t, 82.0, TotalWeight(NewTextDict(lexicon)))
+	restored := d.GetLexicon()
+	for i := 0; i < lexicon.Len(); i++ {
+		assert.Equal(t, lexicon.At(i).ToString(), restored.At(i).ToString())
+	}
+
+	filename := filepath.Join(t.TempDir(), "weighted.bin")
+	require.NoError(t, d.SerializeToFile(filename))
+	mapped, err := NewMmapDictFromFile(filename)
+	require.NoError(t, err)
+	defer mapped.Close()
+	require.NoError(t, mapped.Verify())
+	weight, _ = EntryWeight(mapped.Match("分子"))
+	assert.Equal(t, 80.0, weight)
+
+	group := NewDictGroup([]Dict{NewSourceDict(mapped, filename, nil), mustCompile(t, newTestLexicon())})
+	assert.Equal(t, 82.0+7, TotalWeight(group))
+	assert.Equal(t, TotalWeight(group), TotalWeight(NewTextDict(group.GetLexicon())))
+
+	// Unweighted dictionaries store no weights table
+	plain := mustCompile(t, newTestLexicon())
+	assert.Empty(t, plain.weights)
+	assert.Equal(t, 7.0, plain.TotalWeight())
+	var unweighted bytes.Buffer
+	require.NoError(t, plain.SerializeToWriter(&unweighted))
+
+	flagged := append([]byte(nil), unweighted.Bytes()...)
+	flagged[36] = 2
+	_, err = NewCompiledDictFromBytes(flagged)
+	assert.ErrorIs(t, err, ErrInvalidFormat)
+}
+
+func TestCompiledDictTooLarge(t *testing.T) {
+	defer func(limit uint64) { binaryDictLimit = limit }(binaryDictLimit)
+
+	// newTestLexicon holds 14 strings in 41 bytes
+	binaryDictLimit = 41
+	_, err := NewCompiledDict(newTestLexicon())
+	assert.NoError(t, err)
+
+	binaryDictLimit = 40
+	_, err = NewCompiledDict(newTestLexicon())
+	assert.ErrorIs(t, err, ErrDictTooLarge)
+
+	// 6 strings in 3 bytes
+	lexicon := NewLexicon()
+	for _, key := range []string{"a", "b", "c"} {
+		lexicon.Add(NewStrSingleValueDictEntry(key, ""))
+	}
+	binaryDictLimit = 6
+	_, err = NewCompiledDict(lexicon)
+	assert.NoError(t, err)
+
+	binaryDictLimit = 5
+	_, err = NewCompiledDict(lexicon)
+	assert.ErrorIs(t, err, ErrDictTooLarge)
+}
@@ -0,0 +1,307 @@
+/*
+ * Open Chinese Convert
+ *
+ * Copyright 2010-2020 Carbo Kuo <byvoid@byvoid.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dict
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+)
+
+// DartsDict is a read-only dictionary loaded from a legacy OpenCC .ocd
+// file. The file consists of the DartsDictHeader, a darts-clone double
+// array mapping keys to entry indexes, and the legacy binary lexicon.
+// Upstream wrote the sizes in the file as native size_t values, so the
+// layout depends on the build that wrote it; only the little-endian layout
+// of 64-bit builds is supported, and files from 32-bit builds are rejected.
+type DartsDict struct {
+	maxLength int
+	units     []dartsUnit
+	lexicon   *Lexicon
+}
+
+// NewDartsDictFromFile loads a DartsDict from an .ocd file
+func NewDartsDictFromFile(filename string) (*DartsDict, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return NewDartsDictFromBytes(data)
+}
+
+// NewDartsDictFromReader loads a DartsDict from a reader
+func NewDartsDictFromReader(reader io.Reader) (*DartsDict, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	return NewDartsDictFromBytes(data)
+}
+
+// NewDartsDictFromBytes loads a DartsDict from the contents of an .ocd file
+// written by a 64-bit build of upstream OpenCC
+func NewDartsDictFromBytes(data []byte) (*DartsDict, error) {
+	if !bytes.HasPrefix(data, DartsDictHeader) {
+		return nil, ErrInvalidHeader
+	}
+	reader := &legacyReader{data: data[len(DartsDictHeader):]}
+	if reader.has32BitSizes() {
+		return nil, fmt.Errorf("%w: .ocd files written by 32-bit builds of OpenCC are not supported", ErrInvalidFormat)
+	}
+
+	dartsSize, err := reader.size()
+	if err != nil {
+		return nil, err
+	}
+	array, err := reader.bytes(dartsSize)
+	if err != nil {
+		return nil, err
+	}
+	if len(array)%4 != 0 || len(array) == 0 {
+		return nil, fmt.Errorf("%w: invalid double array size %d", ErrInvalidFormat, len(array))
+	}
+	units := make([]dartsUnit, len(array)/4)
+	for i := range units {
+		units[i] = dartsUnit(binary.LittleEndian.Uint32(array[i*4:]))
+	}
+
+	lexicon, err := readLegacyLexicon(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	maxLength := 0
+	for _, entry := range lexicon.entries {
+		if entry.KeyLength() > maxLength {
+			maxLength = entry.KeyLength()
+		}
+	}
+
+	return &DartsDict{
+		maxLength: maxLength,
+		units:     units,
+		lexicon:   lexicon,
+	}, nil
+}
+
+// legacyReader reads the size_t based layout of legacy OpenCC binary files
+type legacyReader struct {
+	data []byte
+	pos  int
+}
+
+// has32BitSizes reports whether the data starts with the double array size
+// as a 32-bit size_t: read as 64 bits, the size takes the first unit of the
+// array as its upper half and exceeds the data, while its lower half is a
+// whole number of units that fits
+func (r *legacyReader) has32BitSizes() bool {
+	if len(r.data)-r.pos < 8 {
+		return false
+	}
+	size64 := binary.LittleEndian.Uint64(r.data[r.pos:])
+	size32 := uint64(binary.LittleEndian.Uint32(r.data[r.pos:]))
+	return size64 > uint64(len(r.data)) && size32 > 0 && size32%4 == 0 && size32 <= uint64(len(r.data)-r.pos-4)
+}
+
+// size reads a 64-bit size_t value
+func (r *legacyReader) size() (int, error) {
+	if len(r.data)-r.pos < 8 {
+		return 0, fmt.Errorf("%w: unexpected end of dictionary", ErrInvalidFormat)
+	}
+	v := binary.LittleEndian.Uint64(r.data[r.pos:])
+	r.pos += 8
+	if v > uint64(len(r.data)) {
+		return 0, fmt.Errorf("%w: size %d exceeds dictionary size", ErrInvalidFormat, v)
+	}
+	return int(v), nil
+}
+
+// bytes reads n raw bytes
+func (r *legacyReader) bytes(n int) ([]byte, error) {
+	if len(r.data)-r.pos < n {
+		return nil, fmt.Errorf("%w: unexpected end of dictionary", ErrInvalidFormat)
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b, nil
+}
+
+// readLegacyLexicon reads the value table of a legacy .ocd file: a key
+// buffer and a value buffer of NUL-terminated strings followed by, for each
+// entry, its value count, key offset and value offsets.
+func readLegacyLexicon(r *legacyReader) (*Lexicon, error) {
+	numItems, err := r.size()
+	if err != nil {
+		return nil, err
+	}
+	keyLength, err := r.size()
+	if err != nil {
+		return nil, err
+	}
+	keyBuffer, err := r.bytes(keyLength)
+	if err != nil {
+		return nil, err
+	}
+	valueLength, err := r.size()
+	if err != nil {
+		return nil, err
+	}
+	valueBuffer, err := r.bytes(valueLength)
+	if err != nil {
+		return nil, err
+	}
+
+	cString := func(buffer []byte, offset int) (string, error) {
+		if offset >= len(buffer) {
+			return "", fmt.Errorf("%w: string offset out of range", ErrInvalidFormat)
+		}
+		end := bytes.IndexByte(buffer[offset:], 0)
+		if end < 0 {
+			return "", fmt.Errorf("%w: unterminated string", ErrInvalidFormat)
+		}
+		return string(buffer[offset : offset+end]), nil
+	}
+
+	// Every entry needs at least its value count and key offset
+	if numItems > (len(r.data)-r.pos)/16 {
+		return nil, fmt.Errorf("%w: unexpected end of dictionary", ErrInvalidFormat)
+	}
+	lexicon := &Lexicon{entries: make([]DictEntry, 0, numItems)}
+	for i := 0; i < numItems; i++ {
+		numValues, err := r.size()
+		if err != nil {
+			return nil, err
+		}
+		keyOffset, err := r.size()
+		if err != nil {
+			return nil, err
+		}
+		key, err := cString(keyBuffer, keyOffset)
+		if err != nil {
+			return nil, err
+		}
+		values := make([]string, numValues)
+		for j := range values {
+			valueOffset, err := r.size()
+			if err != nil {
+				return nil, err
+			}
+			if values[j], err = cString(valueBuffer, valueOffset); err != nil {
+				return nil, err
+			}
+		}
+		lexicon.Add(EntryFactory.NewMulti(key, values))
+	}
+	return lexicon, nil
+}
+
+// dartsUnit is a unit of a darts-clone double array
+type dartsUnit uint32
+
+// hasLeaf reports whether the node has a value child
+func (u dartsUnit) hasLeaf() bool {
+	return (u>>8)&1 == 1
+}
+
+// value returns the value stored in a leaf unit
+func (u dartsUnit) value() int {
+	return int(u & (1<<31 - 1))
+}
+
+// label returns the label of the unit; leaf units never match a byte
+func (u dartsUnit) label() uint32 {
+	return uint32(u) & (1<<31 | 0xFF)
+}
+
+// offset returns the XOR offset to the children of the unit
+func (u dartsUnit) offset() uint32 {
+	return (uint32(u) >> 10) << ((uint32(u) & (1 << 9)) >> 6)
+}
+
+// commonPrefixSearch calls fn with the entry index and length of every key
+// that is a prefix of word, shortest first
+func (d *DartsDict) commonPrefixSearch(word string, fn func(index, length int)) {
+	nodePos := d.units[0].offset()
+	for i := 0; i < len(word); i++ {
+		nodePos ^= uint32(word[i])
+		if int(nodePos) >= len(d.units) {
+			return
+		}
+		unit := d.units[nodePos]
+		if unit.label() != uint32(word[i]) {
+			return
+		}
+		nodePos ^= unit.offset()
+		if unit.hasLeaf() {
+			if int(nodePos) >= len(d.units) {
+				return
+			}
+			if index := d.units[nodePos].value(); index < d.lexicon.Len() {
+				fn(index, i+1)
+			}
+		}
+	}
+}
+
+// Match performs exact matching
+func (d *DartsDict) Match(word string) DictEntry {
+	if len(word) == 0 || len(word) > d.maxLength {
+		return nil
+	}
+	var entry DictEntry
+	d.commonPrefixSearch(word, func(index, length int) {
+		if length == len(word) {
+			entry = d.lexicon.At(index)
+		}
+	})
+	return entry
+}
+
+// MatchPrefix finds the longest matching prefix
+func (d *DartsDict) MatchPrefix(word string) DictEntry {
+	var entry DictEntry
+	d.commonPrefixSearch(word[:min(len(word), d.maxLength)], func(index, length int) {
+		entry = d.lexicon.At(index)
+	})
+	return entry
+}
+
+// MatchAllPrefixes finds all matching prefixes, sorted by length (descending)
+func (d *DartsDict) MatchAllPrefixes(word string) []DictEntry {
+	var results []DictEntry
+	d.commonPrefixSearch(word[:min(len(word), d.maxLength)], func(index, length int) {
+		results = append(results, d.lexicon.At(index))
+	})
+	for i, j := 0, len(results)-1; i < j; i, j = i+1, j-1 {
+		results[i], results[j] = results[j], results[i]
+	}
+	return results
+}
+
+// KeyMaxLength returns the maximum key length
+func (d *DartsDict) KeyMaxLength() int {
+	return d.maxLength
+}
+
+// GetLexicon returns the lexicon
+func (d *DartsDict) GetLexicon() *Lexicon {
+	return d.lexicon
+}
@@ -0,0 +1,208 @@
+/*
+ * Open Chinese Convert
+ *
+ * Copyright 2010-2020 Carbo Kuo <byvoid@byvoid.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dict
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dartsFixtureBuilder builds a darts-clone double array for test fixtures
+type dartsFixtureBuilder struct {
+	units     []uint32
+	used      []bool
+	usedBases map[int]bool
+	keys      []string
+}
+
+func (b *dartsFixtureBuilder) reserve(pos int) {
+	for len(b.units) <= pos {
+		b.units = append(b.units, 0)
+		b.used = append(b.used, false)
+	}
+}
+
+// build places the children of nodePos for keys[begin:end] sharing a
+// prefix of length depth
+func (b *dartsFixtureBuilder) build(nodePos, begin, end, depth int) {
+	var labels []int
+	for i := begin; i < end; i++ {
+		label := 0
+		if len(b.keys[i]) > depth {
+			label = int(b.keys[i][depth])
+		}
+		if len(labels) == 0 || labels[len(labels)-1] != label {
+			labels = append(labels, label)
+		}
+	}
+
+	base := 1
+	for ; ; base++ {
+		if b.usedBases[base] {
+			continue
+		}
+		free := true
+		for _, label := range labels {
+			pos := base ^ label
+			b.reserve(pos)
+			if pos == 0 || b.used[pos] {
+				free = false
+				break
+			}
+		}
+		if free {
+			break
+		}
+	}
+	b.usedBases[base] = true
+	b.units[nodePos] |= uint32(nodePos^base) << 10
+
+	for i := begin; i < end; {
+		label := 0
+		if len(b.keys[i]) > depth {
+			label = int(b.keys[i][depth])
+		}
+		j := i + 1
+		for j < end && len(b.keys[j]) > depth && int(b.keys[j][depth]) == label && label != 0 {
+			j++
+		}
+		pos := base ^ label
+		b.used[pos] = true
+		if label == 0 {
+			b.units[nodePos] |= 1 << 8
+			b.units[pos] = uint32(i) | 1<<31
+		} else {
+			b.units[pos] = uint32(label)
+			b.build(pos, i, j, depth+1)
+		}
+		i = j
+	}
+}
+
+// buildDartsFixture serializes an .ocd dictionary for the given entries
+func buildDartsFixture(entries map[string][]string) []byte {
+	return buildDartsFixtureSized(entries, 8)
+}
+
+// buildDartsFixtureSized builds an .ocd file whose size_t values are
+// sizeBytes long, 8 for 64-bit builds of OpenCC and 4 for 32-bit ones
+func buildDartsFixtureSized(entries map[string][]string, sizeBytes int) []byte {
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	builder := &dartsFixtureBuilder{keys: keys, usedBases: make(map[int]bool)}
+	builder.reserve(0)
+	builder.used[0] = true
+	builder.build(0, 0, len(keys), 0)
+
+	var w fixtureWriter
+	size := w.u64
+	if sizeBytes == 4 {
+		size = w.u32
+	}
+	w.Write(DartsDictHeader)
+	size(len(builder.units) * 4)
+	for _, unit := range builder.units {
+		w.u32(int(unit))
+	}
+
+	var keyBuffer, valueBuffer []byte
+	var offsets [][]int
+	for _, key := range keys {
+		itemOffsets := []int{len(keyBuffer)}
+		keyBuffer = append(append(keyBuffer, key...), 0)
+		for _, value := range entries[key] {
+			itemOffsets = append(itemOffsets, len(valueBuffer))
+			valueBuffer = append(append(valueBuffer, value...), 0)
+		}
+		offsets = append(offsets, itemOffsets)
+	}
+	size(len(keys))
+	size(len(keyBuffer))
+	w.Write(keyBuffer)
+	size(len(valueBuffer))
+	w.Write(valueBuffer)
+	for _, itemOffsets := range offsets {
+		size(len(itemOffsets) - 1)
+		for _, offset := range itemOffsets {
+			size(offset)
+		}
+	}
+	return w.Bytes()
+}
+
+func TestDartsDict(t *testing.T) {
+	entries := map[string][]string{
+		"a":   {"A"},
+		"ab":  {"AB"},
+		"abc": {"ABC"},
+		"简":   {"簡"},
+		"简体":  {"簡體"},
+		"发":   {"發", "髮"},
+		"汉字":  {"漢字"},
+		"无值":  {},
+	}
+
+	d, err := NewDartsDictFromBytes(buildDartsFixture(entries))
+	require.NoError(t, err)
+
+	assert.Equal(t, len(entries), d.GetLexicon().Len())
+	assert.Equal(t, 6, d.KeyMaxLength())
+	for key, values := range entries {
+		entry := d.Match(key)
+		require.NotNil(t, entry, key)
+		assert.Equal(t, key, entry.Key())
+		assert.Equal(t, len(values), entry.NumValues())
+		if len(values) > 0 {
+			assert.Equal(t, values, entry.Values())
+		}
+	}
+	assert.Nil(t, d.Match("汉"))
+	assert.Nil(t, d.Match("abcd"))
+
+	entry := d.MatchPrefix("简体字")
+	require.NotNil(t, entry)
+	assert.Equal(t, "简体", entry.Key())
+	assert.Nil(t, d.MatchPrefix("中文"))
+
+	all := d.MatchAllPrefixes("abcd")
+	require.Len(t, all, 3)
+	assert.Equal(t, "abc", all[0].Key())
+	assert.Equal(t, "ab", all[1].Key())
+	assert.Equal(t, "a", all[2].Key())
+}
+
+func TestDartsDictInvalid(t *testing.T) {
+	_, err := NewDartsDictFromBytes([]byte("OPENCC_MARISA_0.2.5"))
+	assert.ErrorIs(t, err, ErrInvalidHeader)
+
+	data := buildDartsFixture(map[string][]string{"简体": {"簡體"}})
+	_, err = NewDartsDictFromBytes(data[:len(data)-4])
+	assert.ErrorIs(t, err, ErrInvalidFormat)
+
+	_, err = NewDartsDictFromBytes(buildDartsFixtureSized(map[string][]string{"简体": {"簡體"}}, 4))
+	assert.ErrorIs(t, err, ErrInvalidFormat)
+	assert.ErrorContains(t, err, "32-bit")
+}
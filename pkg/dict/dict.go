@@ -44,6 +44,22 @@ type Dict interface {
 	GetLexicon() *Lexicon
 }
 
+// TotalWeight returns the sum of the weights of all entries in a dictionary,
+// counting entries without a positive weight as DefaultWeight
+// Dictionaries that know their total, such as CompiledDict, report it
+// without reading their entries
+func TotalWeight(d Dict) float64 {
+	if weighted, ok := d.(interface{ TotalWeight() float64 }); ok {
+		return weighted.TotalWeight()
+	}
+	total := 0.0
+	lexicon := d.GetLexicon()
+	for i := 0; i < lexicon.Len(); i++ {
+		total += WeightOrDefault(lexicon.At(i))
+	}
+	return total
+}
+
 // Optional type for representing nullable values
 type Optional struct {
 	value DictEntry
@@ -151,8 +167,8 @@ func (d *TextDict) MatchAllPrefixes(word string) []DictEntry {
 	maxLen := min(len(word), d.maxLength)
 	var results []DictEntry
 
-	// Collect all matching prefixes
-	for l := 1; l <= maxLen; l++ {
+	// Collect all matching prefixes, longest first
+	for l := maxLen; l > 0; l-- {
 		prefix := word[:l]
 		idx := sort.Search(d.lexicon.Len(), func(i int) bool {
 			return d.lexicon.At(i).Key() >= prefix
@@ -210,16 +226,17 @@ func (d *TextDict) SerializeToWriter(writer io.Writer) error {
 	return nil
 }
 
-// BinaryDict represents a binary serialized dictionary
-type BinaryDict struct {
-	Dict
-}
+// BinaryDict represents a binary serialized dictionary.
+//
+// Deprecated: use CompiledDict, which this name now refers to.
+type BinaryDict = CompiledDict
 
 // Common errors for dictionary operations
 var (
 	ErrInvalidFormat  = errors.New("invalid dictionary format")
 	ErrInvalidHeader  = errors.New("invalid dictionary header")
 	ErrInvalidVersion = errors.New("unsupported dictionary version")
+	ErrDictTooLarge   = errors.New("dictionary too large for the binary format")
 )
 
 // DartsDictHeader is the file header for Darts format
@@ -227,3 +244,6 @@ var DartsDictHeader = []byte("OPENCCDARTS1")
 
 // MarisaDictHeader is the file header for Marisa format
 var MarisaDictHeader = []byte("OPENCC_MARISA_0.2.5")
+
+// BinaryDictHeader is the file header for the native binary format
+var BinaryDictHeader = []byte("OPENCCGOBIN1")
@@ -20,6 +20,7 @@
 package dict
 
 import (
+	"strconv"
 	"strings"
 )
 
@@ -227,6 +228,48 @@ func (e *StrMultiValueDictEntry) Equals(other DictEntry) bool {
 	return e.key == other.Key()
 }
 
+// WeightedDictEntry is a dictionary entry with a weight, such as a word
+// frequency, read from the optional third column of a text dictionary
+type WeightedDictEntry struct {
+	DictEntry
+	weight float64
+}
+
+// NewWeightedDictEntry creates a new WeightedDictEntry
+func NewWeightedDictEntry(entry DictEntry, weight float64) *WeightedDictEntry {
+	return &WeightedDictEntry{DictEntry: entry, weight: weight}
+}
+
+// Weight returns the weight of the entry
+func (e *WeightedDictEntry) Weight() float64 {
+	return e.weight
+}
+
+// ToString returns "key\tvalue1 value2 ...\tweight"
+func (e *WeightedDictEntry) ToString() string {
+	return e.Key() + "\t" + strings.Join(e.Values(), " ") + "\t" + strconv.FormatFloat(e.weight, 'g', -1, 64)
+}
+
+// EntryWeight returns the weight of an entry and whether it has one
+func EntryWeight(entry DictEntry) (float64, bool) {
+	if weighted, ok := entry.(interface{ Weight() float64 }); ok {
+		return weighted.Weight(), true
+	}
+	return 0, false
+}
+
+// DefaultWeight is the weight of entries without a positive weight
+const DefaultWeight = 1.0
+
+// WeightOrDefault returns the weight of an entry, or DefaultWeight if it
+// has no positive weight
+func WeightOrDefault(entry DictEntry) float64 {
+	if weight, ok := EntryWeight(entry); ok && weight > 0 {
+		return weight
+	}
+	return DefaultWeight
+}
+
 // DictEntryFactory provides factory methods for creating dictionary entries
 type DictEntryFactory struct{}
 
@@ -260,6 +303,8 @@ func (f *DictEntryFactory) NewFromEntry(entry DictEntry) DictEntry {
 		return NewStrSingleValueDictEntry(e.Key(), e.Value())
 	case *StrMultiValueDictEntry:
 		return NewStrMultiValueDictEntry(e.Key(), e.Values())
+	case *WeightedDictEntry:
+		return NewWeightedDictEntry(f.NewFromEntry(e.DictEntry), e.weight)
 	default:
 		// For unknown types, create based on available methods
 		if entry.NumValues() == 0 {
@@ -19,6 +19,8 @@
 package dict
 
 import (
+	"errors"
+	"io"
 	"sort"
 )
 
@@ -34,14 +36,41 @@ func NewDictGroup(dicts []Dict) *DictGroup {
 	}
 }
 
+// Close closes the dictionaries of the group that hold resources
+func (g *DictGroup) Close() error {
+	var errs []error
+	for _, d := range g.dicts {
+		if closer, ok := d.(io.Closer); ok {
+			errs = append(errs, closer.Close())
+		}
+	}
+	return errors.Join(errs...)
+}
+
 // MatchExact performs exact matching, searching each dictionary in order
 func (g *DictGroup) Match(word string) DictEntry {
-	for _, d := range g.dicts {
+	entry, _ := g.match(word)
+	return entry
+}
+
+// MatchWithProvenance performs exact matching like Match and reports where
+// the matched entry is defined
+func (g *DictGroup) MatchWithProvenance(word string) (DictEntry, Provenance) {
+	if _, index := g.match(word); index >= 0 {
+		return MatchWithProvenance(g.dicts[index], word)
+	}
+	return nil, Provenance{}
+}
+
+// match returns the first exact match and the index of the dictionary it
+// came from, or -1
+func (g *DictGroup) match(word string) (DictEntry, int) {
+	for i, d := range g.dicts {
 		if entry := d.Match(word); entry != nil {
-			return entry
+			return entry, i
 		}
 	}
-	return nil
+	return nil, -1
 }
 
 // MatchPrefix finds the longest matching prefix across all dictionaries
@@ -69,8 +98,9 @@ func (g *DictGroup) MatchAllPrefixes(word string) []DictEntry {
 		allEntries = append(allEntries, entries...)
 	}
 
-	// Sort by key length (descending) and then by key (ascending)
-	sort.Slice(allEntries, func(i, j int) bool {
+	// Sort by key length (descending) and then by key (ascending), keeping
+	// the entry of the earliest dictionary first for duplicate keys
+	sort.SliceStable(allEntries, func(i, j int) bool {
 		if allEntries[i].KeyLength() != allEntries[j].KeyLength() {
 			return allEntries[i].KeyLength() > allEntries[j].KeyLength()
 		}
@@ -117,6 +147,16 @@ func (g *DictGroup) GetLexicon() *Lexicon {
 	return lexicon
 }
 
+// TotalWeight returns the sum of the total weights of all dictionaries,
+// which is the total weight of the merged lexicon
+func (g *DictGroup) TotalWeight() float64 {
+	total := 0.0
+	for _, d := range g.dicts {
+		total += TotalWeight(d)
+	}
+	return total
+}
+
 // Len returns the number of dictionaries in the group
 func (g *DictGroup) Len() int {
 	return len(g.dicts)
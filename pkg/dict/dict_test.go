@@ -19,9 +19,16 @@
 package dict
 
 import (
+	"bufio"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"unicode/utf8"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestStrSingleValueDictEntry(t *testing.T) {
@@ -81,6 +88,35 @@ func TestLexicon(t *testing.T) {
 	assert.Equal(t, "a", dupkey)
 }
 
+func TestParseLexiconWeights(t *testing.T) {
+	input := "分子\t分子\t120\n发\t發 髮\n发\t髪\t發\n"
+	lexicon, err := ParseLexiconFromReader(bufio.NewReader(strings.NewReader(input)))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, lexicon.Len())
+
+	weight, ok := EntryWeight(lexicon.At(0))
+	assert.True(t, ok)
+	assert.Equal(t, 120.0, weight)
+	assert.Equal(t, "分子", lexicon.At(0).GetDefault())
+	assert.Equal(t, "分子\t分子\t120", lexicon.At(0).ToString())
+
+	_, ok = EntryWeight(lexicon.At(1))
+	assert.False(t, ok)
+	assert.Equal(t, []string{"發", "髮"}, lexicon.At(1).Values())
+
+	// A non-numeric third column is another value
+	_, ok = EntryWeight(lexicon.At(2))
+	assert.False(t, ok)
+	assert.Equal(t, []string{"髪", "發"}, lexicon.At(2).Values())
+}
+
+func TestParseLexiconInvalidWeights(t *testing.T) {
+	for _, weight := range []string{"-1", "inf", "NaN", "0x1p4", "1e400"} {
+		_, err := ParseLexiconFromReader(bufio.NewReader(strings.NewReader("分子\t分子\t" + weight + "\n")))
+		assert.ErrorIs(t, err, ErrInvalidFormat, weight)
+	}
+}
+
 func TestTextDict(t *testing.T) {
 	lexicon := NewLexicon()
 	lexicon.Add(NewStrSingleValueDictEntry("简化", "簡化"))
@@ -154,3 +190,53 @@ func TestDictGroup(t *testing.T) {
 	// Test KeyMaxLength
 	assert.Equal(t, 1, group.KeyMaxLength())
 }
+
+// upstreamDictsEnv names a directory of dictionaries compiled by upstream
+// OpenCC, such as /usr/share/opencc of an installed package or the data
+// directory of an upstream build, to test the readers against as well
+const upstreamDictsEnv = "OPENCC_UPSTREAM_DICTS"
+
+// TestUpstreamDicts loads the dictionaries that upstream OpenCC compiled
+// into testdata/upstream and into $OPENCC_UPSTREAM_DICTS. Those with their
+// text source next to them are compared with it; the others are checked
+// to answer a lookup of every key they list.
+func TestUpstreamDicts(t *testing.T) {
+	loaders := map[string]func(filename string) (Dict, error){
+		".ocd2": func(filename string) (Dict, error) { return NewMarisaDictFromFile(filename) },
+		".ocd":  func(filename string) (Dict, error) { return NewDartsDictFromFile(filename) },
+	}
+	dirs := []string{filepath.Join("testdata", "upstream")}
+	if dir := os.Getenv(upstreamDictsEnv); dir != "" {
+		dirs = append(dirs, dir)
+	}
+	var files []string
+	for _, dir := range dirs {
+		for ext := range loaders {
+			matches, err := filepath.Glob(filepath.Join(dir, "*"+ext))
+			require.NoError(t, err)
+			files = append(files, matches...)
+		}
+	}
+	if len(files) == 0 {
+		t.Skipf("no upstream dictionaries in testdata/upstream or $%s, see testdata/upstream/README.md", upstreamDictsEnv)
+	}
+
+	for _, file := range files {
+		d, err := loaders[filepath.Ext(file)](file)
+		require.NoError(t, err, file)
+		lexicon, err := ParseLexiconFromFile(strings.TrimSuffix(file, filepath.Ext(file)) + ".txt")
+		if errors.Is(err, os.ErrNotExist) {
+			lexicon = d.GetLexicon()
+			require.NotZero(t, lexicon.Len(), file)
+		} else {
+			require.NoError(t, err, file)
+		}
+		for i := 0; i < lexicon.Len(); i++ {
+			key := lexicon.At(i).Key()
+			require.True(t, utf8.ValidString(key), "%s: %q", file, key)
+			entry := d.Match(key)
+			require.NotNil(t, entry, "%s: %s", file, key)
+			assert.Equal(t, lexicon.At(i).Values(), entry.Values(), file)
+		}
+	}
+}
@@ -20,8 +20,12 @@ package dict
 
 import (
 	"bufio"
+	"errors"
+	"fmt"
+	"math"
 	"os"
 	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -78,8 +82,9 @@ func (l *Lexicon) Iterator() *LexiconIterator {
 }
 
 // Sort sorts the lexicon by key
+// Entries with the same key keep their relative order
 func (l *Lexicon) Sort() {
-	sort.Slice(l.entries, func(i, j int) bool {
+	sort.SliceStable(l.entries, func(i, j int) bool {
 		return l.entries[i].Key() < l.entries[j].Key()
 	})
 }
@@ -164,17 +169,31 @@ func ParseLexiconFromReader(reader *bufio.Reader) (*Lexicon, error) {
 		}
 
 		// Parse tab-separated values
-		parts := strings.SplitN(line, "\t", 2)
+		parts := strings.Split(line, "\t")
 		key := parts[0]
 
+		// An optional third column holds a numeric weight
+		weight, weighted := 0.0, false
+		if len(parts) == 3 {
+			if w, ok, err := parseWeight(parts[2]); err != nil {
+				return nil, fmt.Errorf("%w: line %d: %v", ErrInvalidFormat, lineNum, err)
+			} else if ok {
+				weight, weighted = w, true
+				parts = parts[:2]
+			}
+		}
+
 		var values []string
 		if len(parts) > 1 {
 			// Multiple values are space-separated (after the tab)
-			values = strings.Fields(parts[1])
+			values = strings.Fields(strings.Join(parts[1:], " "))
 		}
 
 		// Create entry
 		entry := EntryFactory.NewMulti(key, values)
+		if weighted {
+			entry = NewWeightedDictEntry(entry, weight)
+		}
 		lexicon.Add(entry)
 
 		if err != nil {
@@ -216,3 +235,17 @@ func (l *Lexicon) Entries() []DictEntry {
 func (l *Lexicon) SetEntries(entries []DictEntry) {
 	l.entries = entries
 }
+
+// parseWeight parses the weight column of a text dictionary. A column that
+// is not a number is not a weight; one that is a number but not a finite,
+// non-negative decimal is an error.
+func parseWeight(s string) (float64, bool, error) {
+	w, err := strconv.ParseFloat(s, 64)
+	if errors.Is(err, strconv.ErrSyntax) {
+		return 0, false, nil
+	}
+	if err != nil || w < 0 || math.IsInf(w, 0) || math.IsNaN(w) || strings.ContainsAny(s, "xX") {
+		return 0, false, fmt.Errorf("invalid weight %q", s)
+	}
+	return w, true, nil
+}
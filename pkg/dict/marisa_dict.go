@@ -0,0 +1,187 @@
+/*
+ * Open Chinese Convert
+ *
+ * Copyright 2010-2020 Carbo Kuo <byvoid@byvoid.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dict
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+)
+
+// MarisaDict is a read-only dictionary loaded from an upstream OpenCC
+// .ocd2 file. The file consists of the MarisaDictHeader, a serialized
+// marisa trie holding the keys and a table of values indexed by key id.
+type MarisaDict struct {
+	maxLength int
+	trie      *marisaTrie
+	lexicon   *Lexicon
+}
+
+// NewMarisaDictFromFile loads a MarisaDict from an .ocd2 file
+func NewMarisaDictFromFile(filename string) (*MarisaDict, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return NewMarisaDictFromBytes(data)
+}
+
+// NewMarisaDictFromReader loads a MarisaDict from a reader
+func NewMarisaDictFromReader(reader io.Reader) (*MarisaDict, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	return NewMarisaDictFromBytes(data)
+}
+
+// NewMarisaDictFromBytes loads a MarisaDict from the contents of an .ocd2 file
+func NewMarisaDictFromBytes(data []byte) (*MarisaDict, error) {
+	if !bytes.HasPrefix(data, MarisaDictHeader) {
+		return nil, ErrInvalidHeader
+	}
+
+	reader := &marisaReader{data: data, pos: len(MarisaDictHeader)}
+	trie, err := readMarisaTrie(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := readSerializedValues(data[reader.pos:])
+	if err != nil {
+		return nil, err
+	}
+	if len(values) != trie.numKeys() {
+		return nil, fmt.Errorf("%w: %d keys but %d value entries", ErrInvalidFormat, trie.numKeys(), len(values))
+	}
+
+	// Restore every key so that entries can be addressed by key id
+	entries := make([]DictEntry, len(values))
+	maxLength := 0
+	for id := range entries {
+		key := trie.reverseLookup(id)
+		if len(key) > maxLength {
+			maxLength = len(key)
+		}
+		entries[id] = EntryFactory.NewMulti(key, values[id])
+	}
+
+	return &MarisaDict{
+		maxLength: maxLength,
+		trie:      trie,
+		lexicon:   &Lexicon{entries: entries},
+	}, nil
+}
+
+// readSerializedValues reads the value table of an .ocd2 file. Values are
+// stored as one NUL-terminated buffer followed by per-entry value counts
+// and byte lengths.
+func readSerializedValues(data []byte) ([][]string, error) {
+	truncated := fmt.Errorf("%w: truncated value table", ErrInvalidFormat)
+	if len(data) < 8 {
+		return nil, truncated
+	}
+	numItems := binary.LittleEndian.Uint32(data)
+	totalLength := binary.LittleEndian.Uint32(data[4:])
+	data = data[8:]
+	if uint64(totalLength) > uint64(len(data)) {
+		return nil, truncated
+	}
+	buffer := data[:totalLength]
+	data = data[totalLength:]
+
+	// Every item needs at least its value count
+	if uint64(numItems)*2 > uint64(len(data)) {
+		return nil, truncated
+	}
+	values := make([][]string, numItems)
+	cursor := 0
+	for i := range values {
+		if len(data) < 2 {
+			return nil, truncated
+		}
+		numValues := int(binary.LittleEndian.Uint16(data))
+		data = data[2:]
+		if len(data) < numValues*2 {
+			return nil, truncated
+		}
+		values[i] = make([]string, numValues)
+		for j := 0; j < numValues; j++ {
+			numBytes := int(binary.LittleEndian.Uint16(data[j*2:]))
+			if numBytes == 0 || cursor+numBytes > len(buffer) {
+				return nil, fmt.Errorf("%w: value offset out of range", ErrInvalidFormat)
+			}
+			// The stored length includes the NUL terminator
+			values[i][j] = string(buffer[cursor : cursor+numBytes-1])
+			cursor += numBytes
+		}
+		data = data[numValues*2:]
+	}
+	return values, nil
+}
+
+// Match performs exact matching
+func (d *MarisaDict) Match(word string) DictEntry {
+	if len(word) > d.maxLength {
+		return nil
+	}
+	if id, ok := d.trie.lookup(word); ok {
+		return d.lexicon.At(id)
+	}
+	return nil
+}
+
+// MatchPrefix finds the longest matching prefix
+func (d *MarisaDict) MatchPrefix(word string) DictEntry {
+	var entry DictEntry
+	d.trie.commonPrefixSearch(word, func(id, length int) bool {
+		if length > 0 {
+			entry = d.lexicon.At(id)
+		}
+		return true
+	})
+	return entry
+}
+
+// MatchAllPrefixes finds all matching prefixes, sorted by length (descending)
+func (d *MarisaDict) MatchAllPrefixes(word string) []DictEntry {
+	var results []DictEntry
+	d.trie.commonPrefixSearch(word, func(id, length int) bool {
+		if length > 0 {
+			results = append(results, d.lexicon.At(id))
+		}
+		return true
+	})
+	for i, j := 0, len(results)-1; i < j; i, j = i+1, j-1 {
+		results[i], results[j] = results[j], results[i]
+	}
+	return results
+}
+
+// KeyMaxLength returns the maximum key length
+func (d *MarisaDict) KeyMaxLength() int {
+	return d.maxLength
+}
+
+// GetLexicon returns the entries ordered by their key id in the trie
+func (d *MarisaDict) GetLexicon() *Lexicon {
+	return d.lexicon
+}
@@ -0,0 +1,347 @@
+/*
+ * Open Chinese Convert
+ *
+ * Copyright 2010-2020 Carbo Kuo <byvoid@byvoid.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dict
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math/bits"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Link storage modes for the marisa test fixture
+const (
+	fixtureNoLinks = iota
+	fixtureTailLinks
+	fixtureNextTrie
+)
+
+// fixtureNode is a node of the trie used to build marisa test fixtures
+type fixtureNode struct {
+	label    string
+	children []*fixtureNode
+	terminal bool
+}
+
+// insert adds key below the node, one byte per edge
+func (n *fixtureNode) insert(key string) {
+	if key == "" {
+		n.terminal = true
+		return
+	}
+	for _, child := range n.children {
+		if child.label[0] == key[0] {
+			child.insert(key[1:])
+			return
+		}
+	}
+	child := &fixtureNode{label: key[:1]}
+	n.children = append(n.children, child)
+	sort.Slice(n.children, func(i, j int) bool { return n.children[i].label < n.children[j].label })
+	child.insert(key[1:])
+}
+
+// compress merges chains of non-terminal single-child nodes into one edge
+func (n *fixtureNode) compress() {
+	for _, child := range n.children {
+		for !child.terminal && len(child.children) == 1 {
+			grandchild := child.children[0]
+			child.label += grandchild.label
+			child.children = grandchild.children
+			child.terminal = grandchild.terminal
+		}
+		child.compress()
+	}
+}
+
+// bfs returns the nodes in breadth-first order, which is the node id order
+func (n *fixtureNode) bfs() []*fixtureNode {
+	nodes := []*fixtureNode{n}
+	for i := 0; i < len(nodes); i++ {
+		nodes = append(nodes, nodes[i].children...)
+	}
+	return nodes
+}
+
+type fixtureWriter struct {
+	bytes.Buffer
+}
+
+func (w *fixtureWriter) u16(v int) { binary.Write(w, binary.LittleEndian, uint16(v)) }
+func (w *fixtureWriter) u32(v int) { binary.Write(w, binary.LittleEndian, uint32(v)) }
+func (w *fixtureWriter) u64(v int) { binary.Write(w, binary.LittleEndian, uint64(v)) }
+
+func (w *fixtureWriter) vector(data []byte) {
+	w.u64(len(data))
+	w.Write(data)
+	w.Write(make([]byte, (8-len(data)%8)%8))
+}
+
+func (w *fixtureWriter) bitVector(flags []bool) {
+	raw := make([]byte, (len(flags)+63)/64*8)
+	ones := 0
+	for i, f := range flags {
+		if f {
+			raw[i/8] |= 1 << uint(i%8)
+			ones++
+		}
+	}
+	w.vector(raw)
+	w.u32(len(flags))
+	w.u32(ones)
+	w.vector(nil) // ranks
+	w.vector(nil) // select0s
+	w.vector(nil) // select1s
+}
+
+func (w *fixtureWriter) flatVector(values []int) {
+	maxValue := 0
+	for _, v := range values {
+		if v > maxValue {
+			maxValue = v
+		}
+	}
+	valueSize := bits.Len(uint(maxValue))
+	raw := make([]byte, (len(values)*valueSize+63)/64*8)
+	for i, v := range values {
+		for b := 0; b < valueSize; b++ {
+			if v&(1<<uint(b)) != 0 {
+				pos := i*valueSize + b
+				raw[pos/8] |= 1 << uint(pos%8)
+			}
+		}
+	}
+	w.vector(raw)
+	w.u32(valueSize)
+	w.u32(1<<uint(valueSize) - 1)
+	w.u64(len(values))
+}
+
+// writeLevel writes one trie level. Multi-byte labels are resolved by
+// the links function, which may write further levels itself.
+func (w *fixtureWriter) writeLevel(root *fixtureNode, tail []byte, links map[*fixtureNode]int, next func()) {
+	nodes := root.bfs()
+	louds := []bool{true, false}
+	terminals := make([]bool, len(nodes))
+	linkFlags := make([]bool, len(nodes))
+	bases := make([]byte, len(nodes))
+	var extras []int
+	for i, node := range nodes {
+		for range node.children {
+			louds = append(louds, true)
+		}
+		louds = append(louds, false)
+		terminals[i] = node.terminal
+		if i == 0 {
+			continue
+		}
+		if len(node.label) > 1 {
+			linkFlags[i] = true
+			bases[i] = byte(links[node] & 0xFF)
+			extras = append(extras, links[node]>>8)
+		} else {
+			bases[i] = node.label[0]
+		}
+	}
+	w.bitVector(louds)
+	w.bitVector(terminals)
+	w.bitVector(linkFlags)
+	w.vector(bases)
+	w.flatVector(extras)
+	w.vector(tail)
+	w.bitVector(nil)
+	if next != nil {
+		next()
+	}
+	w.vector(nil) // cache
+	w.u32(len(root.children))
+	w.u32(0)
+}
+
+// buildMarisaFixture serializes an .ocd2 dictionary for the given entries
+func buildMarisaFixture(entries map[string][]string, mode int) []byte {
+	root := &fixtureNode{}
+	for key := range entries {
+		root.insert(key)
+	}
+	if mode != fixtureNoLinks {
+		root.compress()
+	}
+
+	var w fixtureWriter
+	w.Write(MarisaDictHeader)
+	w.Write(marisaTrieHeader)
+
+	links := make(map[*fixtureNode]int)
+	var tail []byte
+	var next func()
+	switch mode {
+	case fixtureTailLinks:
+		for _, node := range root.bfs()[1:] {
+			if len(node.label) > 1 {
+				links[node] = len(tail)
+				tail = append(append(tail, node.label...), 0)
+			}
+		}
+	case fixtureNextTrie:
+		// Labels are stored as reversed keys of an uncompressed nested trie
+		nextRoot := &fixtureNode{}
+		for _, node := range root.bfs()[1:] {
+			if len(node.label) > 1 {
+				reversed := []byte(node.label)
+				reverseBytes(reversed)
+				nextRoot.insert(string(reversed))
+			}
+		}
+		ids := make(map[*fixtureNode]int)
+		for i, node := range nextRoot.bfs() {
+			ids[node] = i
+		}
+		for _, node := range root.bfs()[1:] {
+			if len(node.label) > 1 {
+				cur := nextRoot
+				for i := len(node.label) - 1; i >= 0; i-- {
+					for _, child := range cur.children {
+						if child.label[0] == node.label[i] {
+							cur = child
+							break
+						}
+					}
+				}
+				links[node] = ids[cur]
+			}
+		}
+		next = func() { w.writeLevel(nextRoot, nil, nil, nil) }
+	}
+	w.writeLevel(root, tail, links, next)
+
+	// Values are ordered by key id, which follows the terminal node order
+	var ordered [][]string
+	keysByNode := make(map[*fixtureNode]string)
+	var collect func(node *fixtureNode, prefix string)
+	collect = func(node *fixtureNode, prefix string) {
+		keysByNode[node] = prefix
+		for _, child := range node.children {
+			collect(child, prefix+child.label)
+		}
+	}
+	collect(root, "")
+	for _, node := range root.bfs() {
+		if node.terminal {
+			ordered = append(ordered, entries[keysByNode[node]])
+		}
+	}
+
+	var buffer []byte
+	for _, values := range ordered {
+		for _, v := range values {
+			buffer = append(append(buffer, v...), 0)
+		}
+	}
+	w.u32(len(ordered))
+	w.u32(len(buffer))
+	w.Write(buffer)
+	for _, values := range ordered {
+		w.u16(len(values))
+		for _, v := range values {
+			w.u16(len(v) + 1)
+		}
+	}
+	return w.Bytes()
+}
+
+func TestMarisaDict(t *testing.T) {
+	entries := map[string][]string{
+		"a":          {"A"},
+		"ab":         {"AB"},
+		"abcdef":     {"ABCDEF"},
+		"abcdxyz":    {"ABCDXYZ"},
+		"简":          {"簡"},
+		"简体":         {"簡體"},
+		"简体字":        {"簡體字"},
+		"发":          {"發", "髮"},
+		"汉字":         {"漢字"},
+		"头发":         {"頭髮"},
+		"zzzzzzzzzz": {},
+	}
+
+	modes := map[string]int{
+		"no links":  fixtureNoLinks,
+		"tail":      fixtureTailLinks,
+		"next trie": fixtureNextTrie,
+	}
+	for name, mode := range modes {
+		t.Run(name, func(t *testing.T) {
+			d, err := NewMarisaDictFromBytes(buildMarisaFixture(entries, mode))
+			require.NoError(t, err)
+
+			assert.Equal(t, len(entries), d.GetLexicon().Len())
+			assert.Equal(t, 10, d.KeyMaxLength())
+			for key, values := range entries {
+				entry := d.Match(key)
+				require.NotNil(t, entry, key)
+				assert.Equal(t, key, entry.Key())
+				assert.Equal(t, len(values), entry.NumValues())
+				if len(values) > 0 {
+					assert.Equal(t, values, entry.Values())
+				}
+			}
+
+			assert.Nil(t, d.Match("abc"))
+			assert.Nil(t, d.Match("abcdefg"))
+			assert.Nil(t, d.Match("不存在"))
+
+			entry := d.MatchPrefix("简体字典")
+			require.NotNil(t, entry)
+			assert.Equal(t, "简体字", entry.Key())
+
+			entry = d.MatchPrefix("abcdxyz!")
+			require.NotNil(t, entry)
+			assert.Equal(t, "abcdxyz", entry.Key())
+
+			entry = d.MatchPrefix("abcdxy")
+			require.NotNil(t, entry)
+			assert.Equal(t, "ab", entry.Key())
+
+			assert.Nil(t, d.MatchPrefix("中文"))
+
+			all := d.MatchAllPrefixes("abcdefgh")
+			require.Len(t, all, 3)
+			assert.Equal(t, "abcdef", all[0].Key())
+			assert.Equal(t, "ab", all[1].Key())
+			assert.Equal(t, "a", all[2].Key())
+		})
+	}
+}
+
+func TestMarisaDictInvalid(t *testing.T) {
+	_, err := NewMarisaDictFromBytes([]byte("not a dictionary"))
+	assert.ErrorIs(t, err, ErrInvalidHeader)
+
+	data := buildMarisaFixture(map[string][]string{"简体": {"簡體"}}, fixtureTailLinks)
+	_, err = NewMarisaDictFromBytes(data[:len(data)-3])
+	assert.ErrorIs(t, err, ErrInvalidFormat)
+
+	_, err = NewMarisaDictFromBytes(data[:len(MarisaDictHeader)+40])
+	assert.Error(t, err)
+}
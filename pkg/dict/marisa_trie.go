@@ -0,0 +1,483 @@
+/*
+ * Open Chinese Convert
+ *
+ * Copyright 2010-2020 Carbo Kuo <byvoid@byvoid.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dict
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+)
+
+// marisaTrieHeader is the header written by marisa::Trie::write
+var marisaTrieHeader = []byte("We love Marisa.\x00")
+
+// marisaReader reads the little-endian primitives used by the marisa-trie
+// serialization from an in-memory buffer
+type marisaReader struct {
+	data []byte
+	pos  int
+}
+
+// uint32 reads a 32-bit unsigned integer
+func (r *marisaReader) uint32() (uint32, error) {
+	if len(r.data)-r.pos < 4 {
+		return 0, fmt.Errorf("%w: unexpected end of marisa trie", ErrInvalidFormat)
+	}
+	v := binary.LittleEndian.Uint32(r.data[r.pos:])
+	r.pos += 4
+	return v, nil
+}
+
+// uint64 reads a 64-bit unsigned integer
+func (r *marisaReader) uint64() (uint64, error) {
+	if len(r.data)-r.pos < 8 {
+		return 0, fmt.Errorf("%w: unexpected end of marisa trie", ErrInvalidFormat)
+	}
+	v := binary.LittleEndian.Uint64(r.data[r.pos:])
+	r.pos += 8
+	return v, nil
+}
+
+// vector reads a marisa Vector: a 64-bit byte size, the raw objects and
+// padding up to the next 8-byte boundary
+func (r *marisaReader) vector() ([]byte, error) {
+	size, err := r.uint64()
+	if err != nil {
+		return nil, err
+	}
+	if size > uint64(len(r.data)-r.pos) {
+		return nil, fmt.Errorf("%w: marisa vector exceeds file size", ErrInvalidFormat)
+	}
+	n := int(size)
+	v := r.data[r.pos : r.pos+n]
+	r.pos += n
+	if pad := (8 - n%8) % 8; pad > 0 {
+		if len(r.data)-r.pos < pad {
+			return nil, fmt.Errorf("%w: unexpected end of marisa trie", ErrInvalidFormat)
+		}
+		r.pos += pad
+	}
+	return v, nil
+}
+
+// words converts the raw bytes of a marisa unit vector into 64-bit words.
+// Units are 32 or 64 bits wide depending on the platform that built the
+// trie, but both share the same little-endian bit layout.
+func words(raw []byte) []uint64 {
+	units := make([]uint64, (len(raw)+7)/8)
+	for i := range units {
+		var buf [8]byte
+		copy(buf[:], raw[i*8:])
+		units[i] = binary.LittleEndian.Uint64(buf[:])
+	}
+	return units
+}
+
+// bitVector is a read-only bit vector with rank and select support
+type bitVector struct {
+	units   []uint64
+	ranks   []int
+	size    int
+	numOnes int
+}
+
+// readBitVector reads a marisa BitVector. The serialized rank and select
+// indexes are skipped and rebuilt in memory.
+func readBitVector(r *marisaReader) (bitVector, error) {
+	var bv bitVector
+	raw, err := r.vector()
+	if err != nil {
+		return bv, err
+	}
+	size, err := r.uint32()
+	if err != nil {
+		return bv, err
+	}
+	numOnes, err := r.uint32()
+	if err != nil {
+		return bv, err
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := r.vector(); err != nil {
+			return bv, err
+		}
+	}
+	if uint64(size) > uint64(len(raw))*8 || numOnes > size {
+		return bv, fmt.Errorf("%w: corrupted marisa bit vector", ErrInvalidFormat)
+	}
+
+	bv.units = words(raw)
+	bv.size = int(size)
+	bv.ranks = make([]int, len(bv.units)+1)
+	for i, unit := range bv.units {
+		bv.ranks[i+1] = bv.ranks[i] + bits.OnesCount64(unit)
+	}
+	bv.numOnes = bv.ranks[len(bv.units)]
+	if bv.numOnes != int(numOnes) {
+		return bv, fmt.Errorf("%w: corrupted marisa bit vector", ErrInvalidFormat)
+	}
+	return bv, nil
+}
+
+// get returns the bit at position i
+func (bv *bitVector) get(i int) bool {
+	if i < 0 || i >= bv.size {
+		return false
+	}
+	return bv.units[i/64]&(1<<uint(i%64)) != 0
+}
+
+// rank1 returns the number of set bits in [0, i)
+func (bv *bitVector) rank1(i int) int {
+	unit := i / 64
+	n := bv.ranks[unit]
+	if offset := uint(i % 64); offset != 0 {
+		n += bits.OnesCount64(bv.units[unit] & (1<<offset - 1))
+	}
+	return n
+}
+
+// select1 returns the position of the k-th (0-based) set bit
+func (bv *bitVector) select1(k int) int {
+	lo, hi := 0, len(bv.units)
+	for lo+1 < hi {
+		mid := (lo + hi) / 2
+		if bv.ranks[mid] <= k {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return lo*64 + selectInWord(bv.units[lo], k-bv.ranks[lo])
+}
+
+// select0 returns the position of the k-th (0-based) unset bit
+func (bv *bitVector) select0(k int) int {
+	zeros := func(unit int) int { return unit*64 - bv.ranks[unit] }
+	lo, hi := 0, len(bv.units)
+	for lo+1 < hi {
+		mid := (lo + hi) / 2
+		if zeros(mid) <= k {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return lo*64 + selectInWord(^bv.units[lo], k-zeros(lo))
+}
+
+// selectInWord returns the position of the k-th (0-based) set bit in w
+func selectInWord(w uint64, k int) int {
+	for ; k > 0; k-- {
+		w &= w - 1
+	}
+	return bits.TrailingZeros64(w)
+}
+
+// flatVector is a read-only array of fixed-width packed integers
+type flatVector struct {
+	units     []uint64
+	valueSize uint
+	mask      uint64
+	size      int
+}
+
+// readFlatVector reads a marisa FlatVector
+func readFlatVector(r *marisaReader) (flatVector, error) {
+	var fv flatVector
+	raw, err := r.vector()
+	if err != nil {
+		return fv, err
+	}
+	valueSize, err := r.uint32()
+	if err != nil {
+		return fv, err
+	}
+	mask, err := r.uint32()
+	if err != nil {
+		return fv, err
+	}
+	size, err := r.uint64()
+	if err != nil {
+		return fv, err
+	}
+	if valueSize > 32 || size*uint64(valueSize) > uint64(len(raw))*8 {
+		return fv, fmt.Errorf("%w: corrupted marisa flat vector", ErrInvalidFormat)
+	}
+	fv.units = words(raw)
+	fv.valueSize = uint(valueSize)
+	fv.mask = uint64(mask)
+	fv.size = int(size)
+	return fv, nil
+}
+
+// get returns the i-th value
+func (fv *flatVector) get(i int) int {
+	if fv.valueSize == 0 {
+		return 0
+	}
+	pos := uint(i) * fv.valueSize
+	unit, offset := pos/64, pos%64
+	v := fv.units[unit] >> offset
+	if offset+fv.valueSize > 64 {
+		v |= fv.units[unit+1] << (64 - offset)
+	}
+	return int(v & fv.mask)
+}
+
+// marisaTail stores the labels of multi-byte edges of the last trie level
+type marisaTail struct {
+	buf      []byte
+	endFlags bitVector
+}
+
+// restore appends the label starting at offset to buf
+func (t *marisaTail) restore(buf []byte, offset int) []byte {
+	if t.endFlags.size == 0 {
+		for ; offset < len(t.buf) && t.buf[offset] != 0; offset++ {
+			buf = append(buf, t.buf[offset])
+		}
+		return buf
+	}
+	for ; offset < len(t.buf); offset++ {
+		buf = append(buf, t.buf[offset])
+		if t.endFlags.get(offset) {
+			break
+		}
+	}
+	return buf
+}
+
+// marisaTrie is a read-only LOUDS trie in the marisa-trie 0.2.x layout.
+// Multi-byte edge labels are stored either in a tail buffer or, for
+// recursive tries, as reversed keys of a nested trie.
+type marisaTrie struct {
+	louds         bitVector
+	terminalFlags bitVector
+	linkFlags     bitVector
+	bases         []byte
+	extras        flatVector
+	tail          marisaTail
+	next          *marisaTrie
+	numL1Nodes    int
+}
+
+// readMarisaTrie reads a serialized marisa::Trie including its header
+func readMarisaTrie(r *marisaReader) (*marisaTrie, error) {
+	if len(r.data)-r.pos < len(marisaTrieHeader) ||
+		string(r.data[r.pos:r.pos+len(marisaTrieHeader)]) != string(marisaTrieHeader) {
+		return nil, fmt.Errorf("%w: missing marisa trie header", ErrInvalidHeader)
+	}
+	r.pos += len(marisaTrieHeader)
+	return readMarisaLevel(r)
+}
+
+// readMarisaLevel reads one level of a (possibly recursive) marisa trie
+func readMarisaLevel(r *marisaReader) (*marisaTrie, error) {
+	t := &marisaTrie{}
+	var err error
+	if t.louds, err = readBitVector(r); err != nil {
+		return nil, err
+	}
+	if t.terminalFlags, err = readBitVector(r); err != nil {
+		return nil, err
+	}
+	if t.linkFlags, err = readBitVector(r); err != nil {
+		return nil, err
+	}
+	if t.bases, err = r.vector(); err != nil {
+		return nil, err
+	}
+	if t.extras, err = readFlatVector(r); err != nil {
+		return nil, err
+	}
+	if t.tail.buf, err = r.vector(); err != nil {
+		return nil, err
+	}
+	if t.tail.endFlags, err = readBitVector(r); err != nil {
+		return nil, err
+	}
+	if t.linkFlags.numOnes != 0 && len(t.tail.buf) == 0 {
+		if t.next, err = readMarisaLevel(r); err != nil {
+			return nil, err
+		}
+	}
+	// The cache only accelerates lookups and is not needed here
+	if _, err = r.vector(); err != nil {
+		return nil, err
+	}
+	numL1Nodes, err := r.uint32()
+	if err != nil {
+		return nil, err
+	}
+	if _, err = r.uint32(); err != nil { // config flags
+		return nil, err
+	}
+	t.numL1Nodes = int(numL1Nodes)
+
+	numNodes := t.louds.numOnes
+	if len(t.bases) != numNodes || t.terminalFlags.size < numNodes ||
+		t.linkFlags.size < numNodes || t.extras.size != t.linkFlags.numOnes {
+		return nil, fmt.Errorf("%w: inconsistent marisa trie", ErrInvalidFormat)
+	}
+	for node := 1; node < numNodes; node++ {
+		if !t.linkFlags.get(node) {
+			continue
+		}
+		link := t.link(node)
+		if (t.next == nil && link >= len(t.tail.buf)) || (t.next != nil && link >= len(t.next.bases)) {
+			return nil, fmt.Errorf("%w: marisa link out of range", ErrInvalidFormat)
+		}
+	}
+	return t, nil
+}
+
+// numKeys returns the number of keys stored in the trie
+func (t *marisaTrie) numKeys() int {
+	return t.terminalFlags.numOnes
+}
+
+// parent returns the parent node of a non-root node
+func (t *marisaTrie) parent(node int) int {
+	return t.louds.select1(node) - node - 1
+}
+
+// link returns the link of a node whose edge label is stored elsewhere
+func (t *marisaTrie) link(node int) int {
+	return int(t.bases[node]) | t.extras.get(t.linkFlags.rank1(node))<<8
+}
+
+// restore appends the label referenced by link to buf
+func (t *marisaTrie) restore(buf []byte, link int) []byte {
+	if t.next != nil {
+		return t.next.restoreFrom(buf, link)
+	}
+	return t.tail.restore(buf, link)
+}
+
+// restoreFrom appends the labels on the path from node up to the root
+func (t *marisaTrie) restoreFrom(buf []byte, node int) []byte {
+	for {
+		if t.linkFlags.get(node) {
+			buf = t.restore(buf, t.link(node))
+		} else {
+			buf = append(buf, t.bases[node])
+		}
+		if node <= t.numL1Nodes {
+			return buf
+		}
+		node = t.parent(node)
+	}
+}
+
+// firstByte returns the first byte of the label referenced by link
+func (t *marisaTrie) firstByte(link int) byte {
+	if t.next == nil {
+		return t.tail.buf[link]
+	}
+	next := t.next
+	if next.linkFlags.get(link) {
+		return next.firstByte(next.link(link))
+	}
+	return next.bases[link]
+}
+
+// reverseLookup restores the key with the given id
+func (t *marisaTrie) reverseLookup(id int) string {
+	node := t.terminalFlags.select1(id)
+	var buf []byte
+	for node != 0 {
+		if t.linkFlags.get(node) {
+			start := len(buf)
+			buf = t.restore(buf, t.link(node))
+			reverseBytes(buf[start:])
+		} else {
+			buf = append(buf, t.bases[node])
+		}
+		if node <= t.numL1Nodes {
+			break
+		}
+		node = t.parent(node)
+	}
+	reverseBytes(buf)
+	return string(buf)
+}
+
+// commonPrefixSearch calls fn with the id and length of every key that is
+// a prefix of word, shortest first. Iteration stops when fn returns false.
+func (t *marisaTrie) commonPrefixSearch(word string, fn func(id, length int) bool) {
+	if t.terminalFlags.get(0) && !fn(t.terminalFlags.rank1(0), 0) {
+		return
+	}
+	var label []byte
+	node, pos := 0, 0
+	for pos < len(word) {
+		loudsPos := t.louds.select0(node) + 1
+		child := loudsPos - node - 1
+		found := false
+		for ; t.louds.get(loudsPos); loudsPos, child = loudsPos+1, child+1 {
+			if !t.linkFlags.get(child) {
+				if t.bases[child] == word[pos] {
+					pos++
+					found = true
+					break
+				}
+				continue
+			}
+			link := t.link(child)
+			if t.firstByte(link) != word[pos] {
+				continue
+			}
+			label = t.restore(label[:0], link)
+			if len(word)-pos < len(label) || word[pos:pos+len(label)] != string(label) {
+				return
+			}
+			pos += len(label)
+			found = true
+			break
+		}
+		if !found {
+			return
+		}
+		node = child
+		if t.terminalFlags.get(node) && !fn(t.terminalFlags.rank1(node), pos) {
+			return
+		}
+	}
+}
+
+// lookup returns the id of word if it is stored in the trie
+func (t *marisaTrie) lookup(word string) (int, bool) {
+	result, found := 0, false
+	t.commonPrefixSearch(word, func(id, length int) bool {
+		if length == len(word) {
+			result, found = id, true
+			return false
+		}
+		return true
+	})
+	return result, found
+}
+
+// reverseBytes reverses b in place
+func reverseBytes(b []byte) {
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+}
@@ -0,0 +1,139 @@
+/*
+ * Open Chinese Convert
+ *
+ * Copyright 2010-2020 Carbo Kuo <byvoid@byvoid.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dict
+
+import (
+	"io"
+	"os"
+	"runtime"
+	"sync"
+)
+
+// MmapDict is a compiled dictionary (see CompiledDict) backed by a read-only
+// memory mapping of its file. Lookups read the mapped pages directly, so the
+// dictionary adds almost nothing to the Go heap and the pages are shared by
+// every converter and process that maps the same file. On platforms without
+// mmap support the file is read into memory instead.
+type MmapDict struct {
+	dict      *CompiledDict
+	mapping   []byte
+	closeOnce sync.Once
+}
+
+// NewMmapDictFromFile maps a compiled dictionary file into memory. Only the
+// header is checked, so pages are read as lookups need them; call Verify to
+// check the whole file.
+func NewMmapDictFromFile(filename string) (*MmapDict, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() < binaryDictHeaderSize {
+		return nil, ErrInvalidHeader
+	}
+	if int64(int(info.Size())) != info.Size() {
+		return nil, ErrInvalidFormat
+	}
+
+	mapping, err := mmapFile(file, int(info.Size()))
+	if err != nil {
+		return nil, err
+	}
+	d, err := newCompiledDict(mapping, false)
+	if err != nil {
+		munmapFile(mapping)
+		return nil, err
+	}
+
+	m := &MmapDict{dict: d, mapping: mapping}
+	runtime.SetFinalizer(m, (*MmapDict).Close)
+	return m, nil
+}
+
+// Close releases the mapping. The dictionary must not be used afterwards.
+// Unreferenced dictionaries are released automatically.
+func (d *MmapDict) Close() error {
+	var err error
+	d.closeOnce.Do(func() {
+		runtime.SetFinalizer(d, nil)
+		err = munmapFile(d.mapping)
+		d.dict, d.mapping = nil, nil
+	})
+	return err
+}
+
+// Verify checks the checksum and the tables of the dictionary, reading the
+// whole file
+func (d *MmapDict) Verify() error {
+	defer runtime.KeepAlive(d)
+	return d.dict.Verify()
+}
+
+// Match performs exact matching
+func (d *MmapDict) Match(word string) DictEntry {
+	defer runtime.KeepAlive(d)
+	return d.dict.Match(word)
+}
+
+// MatchPrefix finds the longest matching prefix
+func (d *MmapDict) MatchPrefix(word string) DictEntry {
+	defer runtime.KeepAlive(d)
+	return d.dict.MatchPrefix(word)
+}
+
+// MatchAllPrefixes finds all matching prefixes, sorted by length (descending)
+func (d *MmapDict) MatchAllPrefixes(word string) []DictEntry {
+	defer runtime.KeepAlive(d)
+	return d.dict.MatchAllPrefixes(word)
+}
+
+// KeyMaxLength returns the maximum key length
+func (d *MmapDict) KeyMaxLength() int {
+	return d.dict.KeyMaxLength()
+}
+
+// TotalWeight returns the sum of the entry weights stored in the header
+func (d *MmapDict) TotalWeight() float64 {
+	defer runtime.KeepAlive(d)
+	return d.dict.TotalWeight()
+}
+
+// GetLexicon materializes all entries into a lexicon
+func (d *MmapDict) GetLexicon() *Lexicon {
+	defer runtime.KeepAlive(d)
+	return d.dict.GetLexicon()
+}
+
+// SerializeToFile serializes the dictionary to a file
+func (d *MmapDict) SerializeToFile(filename string) error {
+	defer runtime.KeepAlive(d)
+	return d.dict.SerializeToFile(filename)
+}
+
+// SerializeToWriter serializes the dictionary to a writer
+func (d *MmapDict) SerializeToWriter(writer io.Writer) error {
+	defer runtime.KeepAlive(d)
+	return d.dict.SerializeToWriter(writer)
+}
@@ -0,0 +1,133 @@
+/*
+ * Open Chinese Convert
+ *
+ * Copyright 2010-2020 Carbo Kuo <byvoid@byvoid.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dict
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMmapDict(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "test.bin")
+	require.NoError(t, mustCompile(t, newTestLexicon()).SerializeToFile(filename))
+
+	d, err := NewMmapDictFromFile(filename)
+	require.NoError(t, err)
+
+	entry := d.Match("简体")
+	require.NotNil(t, entry)
+	assert.Equal(t, "簡體", entry.GetDefault())
+
+	prefix := d.MatchPrefix("abcdef")
+	require.NotNil(t, prefix)
+	assert.Equal(t, "abc", prefix.Key())
+
+	assert.Len(t, d.MatchAllPrefixes("abc"), 3)
+	assert.Equal(t, 6, d.KeyMaxLength())
+	assert.Equal(t, 7, d.GetLexicon().Len())
+
+	// Entries stay valid after the mapping is released
+	require.NoError(t, d.Close())
+	assert.Equal(t, "簡體", entry.GetDefault())
+	assert.NoError(t, d.Close())
+}
+
+func TestMmapDictClosedThroughWrappers(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "test.bin")
+	require.NoError(t, mustCompile(t, newTestLexicon()).SerializeToFile(filename))
+	d, err := NewMmapDictFromFile(filename)
+	require.NoError(t, err)
+
+	group := NewDictGroup([]Dict{NewSourceDict(d, filename, nil), mustCompile(t, newTestLexicon())})
+	require.NoError(t, group.Close())
+	assert.Nil(t, d.mapping)
+}
+
+func TestMmapDictRecompiled(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "test.bin")
+	require.NoError(t, mustCompile(t, newTestLexicon()).SerializeToFile(filename))
+	require.NoError(t, os.Chmod(filename, 0640))
+	d, err := NewMmapDictFromFile(filename)
+	require.NoError(t, err)
+	defer d.Close()
+
+	// Recompiling a smaller dictionary over the mapped file must not
+	// truncate the pages the mapping still reads
+	lexicon := NewLexicon()
+	lexicon.Add(NewStrSingleValueDictEntry("a", "X"))
+	require.NoError(t, mustCompile(t, lexicon).SerializeToFile(filename))
+
+	entry := d.Match("简体")
+	require.NotNil(t, entry)
+	assert.Equal(t, "簡體", entry.GetDefault())
+
+	info, err := os.Stat(filename)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0640), info.Mode().Perm())
+	entries, err := os.ReadDir(filepath.Dir(filename))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	reloaded, err := NewMmapDictFromFile(filename)
+	require.NoError(t, err)
+	defer reloaded.Close()
+	assert.Nil(t, reloaded.Match("简体"))
+	assert.Equal(t, "X", reloaded.Match("a").GetDefault())
+}
+
+func TestMmapDictInvalid(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := NewMmapDictFromFile(filepath.Join(dir, "missing.bin"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	filename := filepath.Join(dir, "invalid.bin")
+	require.NoError(t, os.WriteFile(filename, []byte("too short"), 0644))
+	_, err = NewMmapDictFromFile(filename)
+	assert.ErrorIs(t, err, ErrInvalidHeader)
+}
+
+func TestMmapDictVerify(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "test.bin")
+	require.NoError(t, mustCompile(t, newTestLexicon()).SerializeToFile(filename))
+	data, err := os.ReadFile(filename)
+	require.NoError(t, err)
+
+	// Mapping checks only the header; the tables are checked on request
+	for i := binaryDictHeaderSize; i < len(data); i++ {
+		data[i] = 0xff
+	}
+	require.NoError(t, os.WriteFile(filename, data, 0644))
+	_, err = NewCompiledDictFromFile(filename)
+	assert.ErrorIs(t, err, ErrInvalidFormat)
+
+	d, err := NewMmapDictFromFile(filename)
+	require.NoError(t, err)
+	defer d.Close()
+	assert.ErrorIs(t, d.Verify(), ErrInvalidFormat)
+	assert.NotPanics(t, func() {
+		d.Match("简体")
+		d.MatchAllPrefixes("abc")
+		d.GetLexicon()
+	})
+}
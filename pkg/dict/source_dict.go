@@ -0,0 +1,136 @@
+/*
+ * Open Chinese Convert
+ *
+ * Copyright 2010-2020 Carbo Kuo <byvoid@byvoid.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dict
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// Provenance identifies where a dictionary entry is defined
+type Provenance struct {
+	File string // dictionary file name, empty if unknown
+	Line int    // 1-based line number, or 0 if unknown
+}
+
+// String formats the provenance as file:line
+func (p Provenance) String() string {
+	if p.Line > 0 {
+		return fmt.Sprintf("%s:%d", p.File, p.Line)
+	}
+	return p.File
+}
+
+// Locator is implemented by dictionaries that can report where their
+// entries are defined
+type Locator interface {
+	// MatchWithProvenance performs exact matching and reports where the
+	// matched entry is defined
+	MatchWithProvenance(word string) (DictEntry, Provenance)
+}
+
+// MatchWithProvenance performs exact matching on d and reports where the
+// matched entry is defined, if d knows
+func MatchWithProvenance(d Dict, word string) (DictEntry, Provenance) {
+	if locator, ok := d.(Locator); ok {
+		return locator.MatchWithProvenance(word)
+	}
+	return d.Match(word), Provenance{}
+}
+
+// SourceDict is a dictionary annotated with the file it was loaded from
+type SourceDict struct {
+	Dict
+	file      string
+	open      func() (io.ReadCloser, error)
+	linesOnce sync.Once
+	lines     map[string]int
+}
+
+// NewSourceDict wraps a dictionary loaded from file
+// If open is not nil, it reopens the text source of the dictionary, which
+// is scanned for line numbers the first time an entry is located
+func NewSourceDict(d Dict, file string, open func() (io.ReadCloser, error)) *SourceDict {
+	return &SourceDict{
+		Dict: d,
+		file: file,
+		open: open,
+	}
+}
+
+// Close closes the wrapped dictionary if it holds resources, such as the
+// mapping of an MmapDict
+func (d *SourceDict) Close() error {
+	if closer, ok := d.Dict.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// TotalWeight returns the total weight of the wrapped dictionary
+func (d *SourceDict) TotalWeight() float64 {
+	return TotalWeight(d.Dict)
+}
+
+// File returns the name of the file the dictionary was loaded from
+func (d *SourceDict) File() string {
+	return d.file
+}
+
+// MatchWithProvenance performs exact matching and reports the file and
+// line defining the matched entry
+func (d *SourceDict) MatchWithProvenance(word string) (DictEntry, Provenance) {
+	entry := d.Match(word)
+	if entry == nil {
+		return nil, Provenance{}
+	}
+	d.linesOnce.Do(d.loadLines)
+	return entry, Provenance{File: d.file, Line: d.lines[entry.Key()]}
+}
+
+// loadLines indexes the line of the first definition of every key
+func (d *SourceDict) loadLines() {
+	if d.open == nil {
+		return
+	}
+	source, err := d.open()
+	if err != nil {
+		return
+	}
+	defer source.Close()
+
+	d.lines = make(map[string]int)
+	reader := bufio.NewReader(source)
+	for lineNum := 1; ; lineNum++ {
+		line, err := reader.ReadString('\n')
+		line = strings.TrimRight(line, "\r\n")
+		if len(line) > 0 && !strings.HasPrefix(line, "#") {
+			key, _, _ := strings.Cut(line, "\t")
+			if _, ok := d.lines[key]; !ok {
+				d.lines[key] = lineNum
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,174 @@
+/*
+ * Open Chinese Convert
+ *
+ * Copyright 2010-2020 Carbo Kuo <byvoid@byvoid.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dict
+
+import (
+	"sort"
+)
+
+// trieNode is a node of a TrieDict. The children of a node are stored
+// contiguously and sorted by label.
+type trieNode struct {
+	firstChild  int32
+	numChildren int32
+	entry       int32 // lexicon index, or -1 if no key ends here
+}
+
+// TrieDict is a dictionary backed by a byte-level trie, so that all
+// prefixes of a word are found in a single walk
+type TrieDict struct {
+	maxLength int
+	lexicon   *Lexicon
+	nodes     []trieNode
+	labels    []byte // labels[i] is the label of the edge into node i
+}
+
+// NewTrieDict creates a new TrieDict from a lexicon
+// The lexicon must be sorted
+func NewTrieDict(lexicon *Lexicon) *TrieDict {
+	d := &TrieDict{
+		lexicon: lexicon,
+		nodes:   []trieNode{{entry: -1}},
+		labels:  []byte{0},
+	}
+
+	// Build breadth-first so that siblings are adjacent. Each queued range
+	// holds the sorted keys sharing the node's prefix of length depth.
+	type pending struct {
+		node, begin, end, depth int
+	}
+	queue := []pending{{0, 0, lexicon.Len(), 0}}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+
+		begin := p.begin
+		if begin < p.end && lexicon.At(begin).KeyLength() == p.depth {
+			// Keep the first of duplicate keys, like a binary search would
+			d.nodes[p.node].entry = int32(begin)
+			for begin < p.end && lexicon.At(begin).KeyLength() == p.depth {
+				begin++
+			}
+		}
+
+		d.nodes[p.node].firstChild = int32(len(d.nodes))
+		for i := begin; i < p.end; {
+			label := lexicon.At(i).Key()[p.depth]
+			j := i + 1
+			for j < p.end && lexicon.At(j).Key()[p.depth] == label {
+				j++
+			}
+			queue = append(queue, pending{len(d.nodes), i, j, p.depth + 1})
+			d.nodes = append(d.nodes, trieNode{entry: -1})
+			d.labels = append(d.labels, label)
+			i = j
+		}
+		d.nodes[p.node].numChildren = int32(len(d.nodes)) - d.nodes[p.node].firstChild
+
+		if p.depth > d.maxLength {
+			d.maxLength = p.depth
+		}
+	}
+
+	return d
+}
+
+// NewTrieDictFromFile creates a TrieDict from a text file
+func NewTrieDictFromFile(filename string) (*TrieDict, error) {
+	lexicon, err := ParseLexiconFromFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	lexicon.Sort()
+	return NewTrieDict(lexicon), nil
+}
+
+// child returns the child of node with the given label, or -1
+func (d *TrieDict) child(node int, label byte) int {
+	first := int(d.nodes[node].firstChild)
+	n := int(d.nodes[node].numChildren)
+	labels := d.labels[first : first+n]
+	i := sort.Search(n, func(i int) bool { return labels[i] >= label })
+	if i < n && labels[i] == label {
+		return first + i
+	}
+	return -1
+}
+
+// walk calls fn with every entry whose key is a prefix of word, shortest
+// first. The walk stops when fn returns false.
+func (d *TrieDict) walk(word string, fn func(entry DictEntry) bool) {
+	node := 0
+	for i := 0; i < len(word); i++ {
+		if node = d.child(node, word[i]); node < 0 {
+			return
+		}
+		if e := d.nodes[node].entry; e >= 0 && !fn(d.lexicon.At(int(e))) {
+			return
+		}
+	}
+}
+
+// Match performs exact matching
+func (d *TrieDict) Match(word string) DictEntry {
+	if len(word) == 0 || len(word) > d.maxLength {
+		return nil
+	}
+	var match DictEntry
+	d.walk(word, func(entry DictEntry) bool {
+		if entry.KeyLength() == len(word) {
+			match = entry
+		}
+		return true
+	})
+	return match
+}
+
+// MatchPrefix finds the longest matching prefix
+func (d *TrieDict) MatchPrefix(word string) DictEntry {
+	var longest DictEntry
+	d.walk(word, func(entry DictEntry) bool {
+		longest = entry
+		return true
+	})
+	return longest
+}
+
+// MatchAllPrefixes finds all matching prefixes, sorted by length (descending)
+func (d *TrieDict) MatchAllPrefixes(word string) []DictEntry {
+	var results []DictEntry
+	d.walk(word, func(entry DictEntry) bool {
+		results = append(results, entry)
+		return true
+	})
+	for i, j := 0, len(results)-1; i < j; i, j = i+1, j-1 {
+		results[i], results[j] = results[j], results[i]
+	}
+	return results
+}
+
+// KeyMaxLength returns the maximum key length
+func (d *TrieDict) KeyMaxLength() int {
+	return d.maxLength
+}
+
+// GetLexicon returns the lexicon
+func (d *TrieDict) GetLexicon() *Lexicon {
+	return d.lexicon
+}
@@ -0,0 +1,76 @@
+/*
+ * Open Chinese Convert
+ *
+ * Copyright 2010-2020 Carbo Kuo <byvoid@byvoid.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dict
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrieDict(t *testing.T) {
+	d := NewTrieDict(newTestLexicon())
+
+	entry := d.Match("简体")
+	require.NotNil(t, entry)
+	assert.Equal(t, "簡體", entry.GetDefault())
+	assert.Nil(t, d.Match("简"))
+	assert.Nil(t, d.Match(""))
+
+	entry = d.MatchPrefix("abcdef")
+	require.NotNil(t, entry)
+	assert.Equal(t, "abc", entry.Key())
+	assert.Nil(t, d.MatchPrefix("xyz"))
+
+	all := d.MatchAllPrefixes("abx")
+	require.Len(t, all, 2)
+	assert.Equal(t, "ab", all[0].Key())
+	assert.Equal(t, "a", all[1].Key())
+
+	assert.Equal(t, 6, d.KeyMaxLength())
+}
+
+func TestTrieDictMatchesTextDict(t *testing.T) {
+	alphabet := []string{"a", "b", "简", "体", "发"}
+	random := rand.New(rand.NewSource(1))
+	word := func() string {
+		s := ""
+		for n := random.Intn(5) + 1; n > 0; n-- {
+			s += alphabet[random.Intn(len(alphabet))]
+		}
+		return s
+	}
+
+	lexicon := NewLexicon()
+	for i := 0; i < 200; i++ {
+		lexicon.Add(NewStrSingleValueDictEntry(word(), "v"))
+	}
+	lexicon.Sort()
+	textDict := NewTextDict(lexicon)
+	trieDict := NewTrieDict(lexicon)
+
+	for i := 0; i < 500; i++ {
+		query := word() + word()
+		assert.Equal(t, textDict.Match(query), trieDict.Match(query), query)
+		assert.Equal(t, textDict.MatchPrefix(query), trieDict.MatchPrefix(query), query)
+		assert.Equal(t, textDict.MatchAllPrefixes(query), trieDict.MatchAllPrefixes(query), query)
+	}
+}
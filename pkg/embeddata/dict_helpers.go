@@ -24,3 +24,16 @@ func DictExists(name string) bool {
 	_, err := GetDict(name)
 	return err == nil
 }
+
+// ErrSchemeNotFound is returned when a scheme is not found.
+var ErrSchemeNotFound = errors.New("scheme not found")
+
+// GetScheme returns the scheme content for the given name.
+// The name can be with or without the .txt extension.
+func GetScheme(name string) ([]byte, error) {
+	baseName := strings.TrimSuffix(name, ".txt")
+	if content, ok := EmbeddedScheme[baseName]; ok {
+		return []byte(content), nil
+	}
+	return nil, ErrSchemeNotFound
+}
@@ -0,0 +1,122 @@
+/*
+ * Open Chinese Convert
+ *
+ * Copyright 2010-2014 Carbo Kuo <byvoid@byvoid.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package segmentation
+
+import (
+	"unicode/utf8"
+
+	"github.com/yanmingcao/opencc-go/pkg/dict"
+)
+
+// BackwardMaxMatchSegmentation implements backward maximum matching
+// segmentation, taking the longest dictionary word that ends at the
+// current position and moving from the end of the text to the start
+type BackwardMaxMatchSegmentation struct {
+	dict dict.Dict
+}
+
+// NewBackwardMaxMatchSegmentation creates a new BackwardMaxMatchSegmentation
+func NewBackwardMaxMatchSegmentation(d dict.Dict) *BackwardMaxMatchSegmentation {
+	return &BackwardMaxMatchSegmentation{
+		dict: d,
+	}
+}
+
+// Segment performs backward maximum matching segmentation
+func (s *BackwardMaxMatchSegmentation) Segment(text string) *Segments {
+	var words []string
+	maxLen := s.dict.KeyMaxLength()
+	for end := len(text); end > 0; {
+		length := 0
+		for l := min(maxLen, end); l > 0; l-- {
+			if utf8.RuneStart(text[end-l]) && s.dict.Match(text[end-l:end]) != nil {
+				length = l
+				break
+			}
+		}
+		if length == 0 {
+			// No match found, take one character (or one invalid byte)
+			_, length = utf8.DecodeLastRuneInString(text[:end])
+		}
+		words = append(words, text[end-length:end])
+		end -= length
+	}
+
+	segments := NewSegments()
+	for i := len(words) - 1; i >= 0; i-- {
+		segments.AddManaged(words[i])
+	}
+	return segments
+}
+
+// GetDict returns the dictionary used for segmentation
+func (s *BackwardMaxMatchSegmentation) GetDict() dict.Dict {
+	return s.dict
+}
+
+// BidirectionalMaxMatchSegmentation runs forward and backward maximum
+// matching and keeps the result with fewer segments, then the one with
+// fewer single-character segments. Remaining ties go to backward matching,
+// which is right more often for Chinese.
+type BidirectionalMaxMatchSegmentation struct {
+	forward  *MaxMatchSegmentation
+	backward *BackwardMaxMatchSegmentation
+}
+
+// NewBidirectionalMaxMatchSegmentation creates a new
+// BidirectionalMaxMatchSegmentation
+func NewBidirectionalMaxMatchSegmentation(d dict.Dict) *BidirectionalMaxMatchSegmentation {
+	return &BidirectionalMaxMatchSegmentation{
+		forward:  NewMaxMatchSegmentation(d),
+		backward: NewBackwardMaxMatchSegmentation(d),
+	}
+}
+
+// Segment performs bidirectional maximum matching segmentation
+func (s *BidirectionalMaxMatchSegmentation) Segment(text string) *Segments {
+	forward := s.forward.Segment(text)
+	backward := s.backward.Segment(text)
+
+	if forward.Length() != backward.Length() {
+		if forward.Length() < backward.Length() {
+			return forward
+		}
+		return backward
+	}
+	if countSingleCharacters(forward) < countSingleCharacters(backward) {
+		return forward
+	}
+	return backward
+}
+
+// GetDict returns the dictionary used for segmentation
+func (s *BidirectionalMaxMatchSegmentation) GetDict() dict.Dict {
+	return s.forward.GetDict()
+}
+
+// countSingleCharacters counts the segments made of a single character
+func countSingleCharacters(segments *Segments) int {
+	count := 0
+	for i := 0; i < segments.Length(); i++ {
+		if utf8.RuneCountInString(segments.At(i)) == 1 {
+			count++
+		}
+	}
+	return count
+}
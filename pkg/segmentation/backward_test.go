@@ -0,0 +1,55 @@
+/*
+ * Open Chinese Convert
+ *
+ * Copyright 2010-2014 Carbo Kuo <byvoid@byvoid.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package segmentation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackwardMaxMatchSegmentation(t *testing.T) {
+	d := newWeightedDict(t, "研究\t研究\n研究生\t研究生\n生命\t生命\n起源\t起源\n")
+
+	assert.Equal(t, []string{"研究生", "命", "起源"}, segmentStrings(NewMaxMatchSegmentation(d).Segment("研究生命起源")))
+
+	seg := NewBackwardMaxMatchSegmentation(d)
+	assert.Equal(t, []string{"研究", "生命", "起源"}, segmentStrings(seg.Segment("研究生命起源")))
+	assert.Equal(t, []string{"a", "起源", "\xe5", "\xad"}, segmentStrings(seg.Segment("a起源\xe5\xad")))
+	assert.Equal(t, 0, seg.Segment("").Length())
+}
+
+func TestBidirectionalMaxMatchSegmentation(t *testing.T) {
+	// Same number of segments, fewer single characters backward
+	d := newWeightedDict(t, "研究\t研究\n研究生\t研究生\n生命\t生命\n起源\t起源\n")
+	seg := NewBidirectionalMaxMatchSegmentation(d)
+	assert.Equal(t, []string{"研究", "生命", "起源"}, segmentStrings(seg.Segment("研究生命起源")))
+
+	// Fewer segments forward
+	d = newWeightedDict(t, "abc\tx\ncd\tx\n")
+	assert.Equal(t, []string{"a", "b", "cd"}, segmentStrings(NewBackwardMaxMatchSegmentation(d).Segment("abcd")))
+	seg = NewBidirectionalMaxMatchSegmentation(d)
+	assert.Equal(t, []string{"abc", "d"}, segmentStrings(seg.Segment("abcd")))
+
+	// Fewer segments backward
+	d = newWeightedDict(t, "ab\tx\nbcd\tx\n")
+	assert.Equal(t, []string{"ab", "c", "d"}, segmentStrings(NewMaxMatchSegmentation(d).Segment("abcd")))
+	seg = NewBidirectionalMaxMatchSegmentation(d)
+	assert.Equal(t, []string{"a", "bcd"}, segmentStrings(seg.Segment("abcd")))
+}
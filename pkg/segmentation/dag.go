@@ -0,0 +1,98 @@
+/*
+ * Open Chinese Convert
+ *
+ * Copyright 2010-2014 Carbo Kuo <byvoid@byvoid.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package segmentation
+
+import (
+	"math"
+
+	"github.com/yanmingcao/opencc-go/pkg/dict"
+	"github.com/yanmingcao/opencc-go/pkg/utf8"
+)
+
+// dagUnknownWeight is the weight of a character not in the dictionary
+const dagUnknownWeight = 0.5
+
+// DAGSegmentation builds a directed acyclic graph of all dictionary matches
+// in the text and takes the most probable path through it (Viterbi), with
+// the probability of a word given by its weight
+type DAGSegmentation struct {
+	dict     dict.Dict
+	logTotal float64
+}
+
+// NewDAGSegmentation creates a new DAGSegmentation with the given dictionary
+// Entry weights are read from the optional weight column of text
+// dictionaries, or from compiled dictionaries; entries without a weight
+// count as 1
+func NewDAGSegmentation(d dict.Dict) *DAGSegmentation {
+	return &DAGSegmentation{
+		dict:     d,
+		logTotal: math.Log(max(dict.TotalWeight(d), 1)),
+	}
+}
+
+// Segment segments text along the most probable path of dictionary words
+func (s *DAGSegmentation) Segment(text string) *Segments {
+	segments := NewSegments()
+	n := len(text)
+	if n == 0 {
+		return segments
+	}
+
+	// score[i] is the best log probability of segmenting text[i:], and
+	// next[i] is where the first word of that segmentation ends
+	score := make([]float64, n+1)
+	next := make([]int, n+1)
+	unknown := math.Log(dagUnknownWeight) - s.logTotal
+	for i := n - 1; i >= 0; i-- {
+		charLen := utf8.NextCharLength(text, i)
+		if charLen == 0 || i+charLen > n {
+			charLen = 1
+		}
+		score[i] = math.Inf(-1)
+
+		// Edges are tried longest first, so ties favor longer words
+		covered := false
+		for _, entry := range s.dict.MatchAllPrefixes(text[i:]) {
+			length := entry.KeyLength()
+			if length == 0 {
+				continue
+			}
+			covered = covered || length == charLen
+			if candidate := math.Log(dict.WeightOrDefault(entry)) - s.logTotal + score[i+length]; candidate > score[i] {
+				score[i], next[i] = candidate, i+length
+			}
+		}
+		if !covered {
+			if candidate := unknown + score[i+charLen]; candidate > score[i] {
+				score[i], next[i] = candidate, i+charLen
+			}
+		}
+	}
+
+	for i := 0; i < n; i = next[i] {
+		segments.AddManaged(text[i:next[i]])
+	}
+	return segments
+}
+
+// GetDict returns the dictionary used for segmentation
+func (s *DAGSegmentation) GetDict() dict.Dict {
+	return s.dict
+}
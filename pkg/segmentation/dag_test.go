@@ -0,0 +1,87 @@
+/*
+ * Open Chinese Convert
+ *
+ * Copyright 2010-2014 Carbo Kuo <byvoid@byvoid.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package segmentation
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yanmingcao/opencc-go/pkg/dict"
+)
+
+func newWeightedDict(t *testing.T, text string) dict.Dict {
+	lexicon, err := dict.ParseLexiconFromReader(bufio.NewReader(strings.NewReader(text)))
+	require.NoError(t, err)
+	lexicon.Sort()
+	return dict.NewTrieDict(lexicon)
+}
+
+func segmentStrings(segments *Segments) []string {
+	var result []string
+	for i := 0; i < segments.Length(); i++ {
+		result = append(result, segments.At(i))
+	}
+	return result
+}
+
+func TestDAGSegmentation(t *testing.T) {
+	d := newWeightedDict(t, "结合\t結合\t50\n合成\t合成\t20\n成分\t成分\t10\n分子\t分子\t80\n成\t成\t100\n子\t子\t5\n")
+
+	// Forward maximum matching greedily takes 成分
+	assert.Equal(t, []string{"结合", "成分", "子"}, segmentStrings(NewMaxMatchSegmentation(d).Segment("结合成分子")))
+
+	seg := NewDAGSegmentation(d)
+	assert.Equal(t, []string{"结合", "成", "分子"}, segmentStrings(seg.Segment("结合成分子")))
+	assert.Equal(t, []string{"x", "分子", "y"}, segmentStrings(seg.Segment("x分子y")))
+	assert.Equal(t, []string{"分子", "\xe5", "\xad"}, segmentStrings(seg.Segment("分子\xe5\xad")))
+	assert.Equal(t, 0, seg.Segment("").Length())
+}
+
+func TestDAGSegmentationUnweighted(t *testing.T) {
+	// Without weights, the path with the fewest words wins
+	d := newWeightedDict(t, "研究\t研究\n研究生\t研究生\n生命\t生命\n起源\t起源\n")
+	seg := NewDAGSegmentation(d)
+	assert.Equal(t, []string{"研究", "生命", "起源"}, segmentStrings(seg.Segment("研究生命起源")))
+}
+
+// compiledOnlyDict fails the test if its entries are materialized
+type compiledOnlyDict struct {
+	*dict.CompiledDict
+	t *testing.T
+}
+
+func (d compiledOnlyDict) GetLexicon() *dict.Lexicon {
+	d.t.Error("GetLexicon called on a compiled dictionary")
+	return d.CompiledDict.GetLexicon()
+}
+
+func TestDAGSegmentationCompiled(t *testing.T) {
+	text := newWeightedDict(t, "结合\t結合\t50\n合成\t合成\t20\n成分\t成分\t10\n分子\t分子\t80\n成\t成\t100\n子\t子\t5\n")
+	d, err := dict.NewCompiledDict(text.GetLexicon())
+	require.NoError(t, err)
+	compiled := compiledOnlyDict{d, t}
+
+	seg := NewDAGSegmentation(compiled)
+	assert.Equal(t, NewDAGSegmentation(text).logTotal, seg.logTotal)
+	assert.Equal(t, []string{"结合", "成", "分子"}, segmentStrings(seg.Segment("结合成分子")))
+}
@@ -0,0 +1,187 @@
+/*
+ * Open Chinese Convert
+ *
+ * Copyright 2010-2014 Carbo Kuo <byvoid@byvoid.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package segmentation
+
+import (
+	"regexp"
+	"sort"
+)
+
+// MaxProtectedSpanLength is the length in bytes of the longest protected
+// span. Longer matches, such as a code fence or comment that is never
+// closed, are converted as plain text, so a stream never holds more than
+// this many bytes back waiting for a span to end.
+const MaxProtectedSpanLength = 1 << 20
+
+// urlStop matches characters that end a URL: whitespace, quotes, angle
+// brackets, backquotes and CJK or full-width punctuation
+const urlStop = "\\s<>\"'`\u3000-\u303f\uff00-\uffef"
+
+// Built-in patterns of text that must not be converted
+var (
+	// ProtectURL matches URLs, which may contain Chinese path components
+	ProtectURL = regexp.MustCompile(`(?i)\b(?:[a-z][a-z0-9+.-]*://|www\.)[^` + urlStop + `]*[^` + urlStop + `.,;:!?)\]]`)
+	// ProtectEmail matches email addresses
+	ProtectEmail = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}`)
+	// ProtectFencedCode matches Markdown fenced code blocks
+	ProtectFencedCode = regexp.MustCompile("(?ms)^[ \t]*(```|~~~).*?^[ \t]*(?:```|~~~)[ \t]*$")
+	// ProtectInlineCode matches Markdown inline code
+	ProtectInlineCode = regexp.MustCompile("`[^`\n]+`")
+	// ProtectHTMLTag matches HTML tags, including their attribute values,
+	// and comments
+	ProtectHTMLTag = regexp.MustCompile(`(?s)<!--.*?-->|</?[A-Za-z][A-Za-z0-9-]*(?:\s[^<>]*)?/?>`)
+	// ProtectHTMLEntity matches HTML character references
+	ProtectHTMLEntity = regexp.MustCompile(`&(?:#[0-9]+|#[xX][0-9A-Fa-f]+|[A-Za-z][A-Za-z0-9]*);`)
+)
+
+// protectOpeners match the start of built-in patterns that may not be
+// complete yet when text arrives in chunks: a code fence, comment, tag or
+// inline code that is not closed
+var protectOpeners = map[*regexp.Regexp][]*regexp.Regexp{
+	ProtectFencedCode: {regexp.MustCompile("(?m)^[ \t]*(?:```|~~~)")},
+	ProtectInlineCode: {regexp.MustCompile("`[^`\n]*\\z")},
+	ProtectHTMLTag: {
+		regexp.MustCompile(`<!--`),
+		regexp.MustCompile(`</?[A-Za-z][A-Za-z0-9-]*(?:\s[^<>]*)?/?\z`),
+	},
+}
+
+// DefaultProtectPatterns are the patterns used by NewDefaultProtector
+var DefaultProtectPatterns = []*regexp.Regexp{
+	ProtectFencedCode,
+	ProtectInlineCode,
+	ProtectHTMLTag,
+	ProtectHTMLEntity,
+	ProtectURL,
+	ProtectEmail,
+}
+
+// Protector finds spans of text, such as URLs and code, that must pass
+// through conversion unchanged
+type Protector struct {
+	patterns []*regexp.Regexp
+	openers  []*regexp.Regexp
+}
+
+// NewProtector creates a Protector for the given patterns
+func NewProtector(patterns ...*regexp.Regexp) *Protector {
+	p := &Protector{patterns: patterns}
+	for _, pattern := range patterns {
+		p.openers = append(p.openers, protectOpeners[pattern]...)
+	}
+	return p
+}
+
+// NewDefaultProtector creates a Protector for the built-in patterns and
+// any extra patterns
+func NewDefaultProtector(extra ...*regexp.Regexp) *Protector {
+	patterns := append(append([]*regexp.Regexp(nil), DefaultProtectPatterns...), extra...)
+	return NewProtector(patterns...)
+}
+
+// Spans returns the protected [start, end) byte ranges of text, sorted and
+// with overlapping matches merged. Matches longer than
+// MaxProtectedSpanLength are not protected.
+func (p *Protector) Spans(text string) [][2]int {
+	var spans [][2]int
+	for _, pattern := range p.patterns {
+		for _, match := range pattern.FindAllStringIndex(text, -1) {
+			if match[1] > match[0] && match[1]-match[0] <= MaxProtectedSpanLength {
+				spans = append(spans, [2]int{match[0], match[1]})
+			}
+		}
+	}
+	if len(spans) == 0 {
+		return nil
+	}
+
+	sort.Slice(spans, func(i, j int) bool { return spans[i][0] < spans[j][0] })
+	merged := spans[:1]
+	for _, span := range spans[1:] {
+		last := &merged[len(merged)-1]
+		if span[0] < last[1] {
+			last[1] = max(last[1], span[1])
+		} else {
+			merged = append(merged, span)
+		}
+	}
+	return merged
+}
+
+// StableEnd returns the largest position, at most end, before which the
+// protected spans of text cannot change when more text is appended. Spans
+// that reach past end, and built-in constructs such as code fences that are
+// opened but not closed yet, start at or after it. Spans and openers more
+// than MaxProtectedSpanLength bytes before the end of text can no longer be
+// protected and do not hold end back. Custom patterns that match across
+// more than end bytes are not detected.
+func (p *Protector) StableEnd(text string, end int) int {
+	spans := p.Spans(text)
+	inSpan := func(position int) bool {
+		i := sort.Search(len(spans), func(i int) bool { return spans[i][1] > position })
+		return i < len(spans) && spans[i][0] <= position
+	}
+	limit := len(text) - MaxProtectedSpanLength
+	for _, span := range spans {
+		if span[1] > end && span[0] >= limit {
+			end = min(end, span[0])
+			break
+		}
+	}
+	for _, opener := range p.openers {
+		for _, match := range opener.FindAllStringIndex(text, -1) {
+			if match[0] >= end {
+				break
+			}
+			if match[0] >= limit && !inSpan(match[0]) {
+				end = match[0]
+				break
+			}
+		}
+	}
+	return end
+}
+
+// Segment segments the text between protected spans with seg and adds each
+// protected span as a single protected segment
+func (p *Protector) Segment(seg Segmentation, text string) *Segments {
+	spans := p.Spans(text)
+	if len(spans) == 0 {
+		return seg.Segment(text)
+	}
+
+	segments := NewSegments()
+	appendSegments := func(text string) {
+		if len(text) == 0 {
+			return
+		}
+		part := seg.Segment(text)
+		for i := 0; i < part.Length(); i++ {
+			segments.AddManaged(part.At(i))
+		}
+	}
+	position := 0
+	for _, span := range spans {
+		appendSegments(text[position:span[0]])
+		segments.AddProtected(text[span[0]:span[1]])
+		position = span[1]
+	}
+	appendSegments(text[position:])
+	return segments
+}
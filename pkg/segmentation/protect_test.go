@@ -0,0 +1,114 @@
+/*
+ * Open Chinese Convert
+ *
+ * Copyright 2010-2014 Carbo Kuo <byvoid@byvoid.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package segmentation
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func protectedStrings(text string, p *Protector) []string {
+	var result []string
+	for _, span := range p.Spans(text) {
+		result = append(result, text[span[0]:span[1]])
+	}
+	return result
+}
+
+func TestProtectorSpans(t *testing.T) {
+	p := NewDefaultProtector()
+
+	tests := []struct {
+		text     string
+		expected []string
+	}{
+		{"访问https://例子.com/简体/页面。", []string{"https://例子.com/简体/页面"}},
+		{"见 www.example.com/简体, 谢谢", []string{"www.example.com/简体"}},
+		{"(http://example.com/a)", []string{"http://example.com/a"}},
+		{"联系 admin@example.com。", []string{"admin@example.com"}},
+		{"代码 `简体` 和 `汉字`", []string{"`简体`", "`汉字`"}},
+		{"前\n```go\n// 简体\n```\n后", []string{"```go\n// 简体\n```"}},
+		{`<a title="简体">简体</a>`, []string{`<a title="简体">`, "</a>"}},
+		{"<!-- 简体 -->&nbsp;&#20013;&#x4e2d;", []string{"<!-- 简体 -->", "&nbsp;", "&#20013;", "&#x4e2d;"}},
+		{"简体中文", nil},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, protectedStrings(tt.text, p), tt.text)
+	}
+}
+
+func TestProtectorMergesOverlappingSpans(t *testing.T) {
+	// The URL lies inside the inline code and the user pattern overlaps both
+	p := NewDefaultProtector(regexp.MustCompile(`简体 \x60`))
+	text := "简体 `http://example.com` 简体"
+	assert.Equal(t, [][2]int{{0, len("简体 `http://example.com`")}}, p.Spans(text))
+}
+
+func TestProtectorSegment(t *testing.T) {
+	d := newWeightedDict(t, "简体\t簡體\n")
+	p := NewProtector(regexp.MustCompile(`ID-\S+`))
+
+	segments := p.Segment(NewMaxMatchSegmentation(d), "简体ID-简体 简体")
+	assert.Equal(t, []string{"简体", "ID-简体", " ", "简体"}, segmentStrings(segments))
+	assert.False(t, segments.IsProtected(0))
+	assert.True(t, segments.IsProtected(1))
+	assert.False(t, segments.IsProtected(2))
+	assert.False(t, segments.IsProtected(3))
+
+	segments = p.Segment(NewMaxMatchSegmentation(d), "简体")
+	assert.Equal(t, []string{"简体"}, segmentStrings(segments))
+	assert.False(t, segments.IsProtected(0))
+}
+
+func TestProtectorSpansTooLong(t *testing.T) {
+	p := NewDefaultProtector()
+	comment := "<!--" + strings.Repeat("a", MaxProtectedSpanLength-7) + "-->"
+	assert.Equal(t, [][2]int{{0, len(comment)}}, p.Spans(comment))
+	assert.Nil(t, p.Spans("<!--a"+comment[4:]))
+}
+
+func TestProtectorStableEnd(t *testing.T) {
+	p := NewDefaultProtector()
+	tests := []struct {
+		name string
+		text string
+		end  int
+		want int
+	}{
+		{"nothing protected", "简体汉字", 6, 6},
+		{"closed span", "`简体` 汉字", 9, 9},
+		{"span past end", "ab https://example.com/x", 10, 3},
+		{"open fence", "ab\n```\n简体", 8, 3},
+		{"closed fence", "ab\n```\n简体\n```\nabc", 18, 18},
+		{"open comment", "ab<!-- 简体", 6, 2},
+		{"open tag", "ab<b title=\"x", 6, 2},
+		{"closed tag", "a<b c>d", 6, 6},
+		{"open inline code", "ab `简体", 6, 3},
+		{"fence open too long", "ab\n```\n" + strings.Repeat("a", MaxProtectedSpanLength), 10, 10},
+		{"comment open too long", "ab<!--" + strings.Repeat("a", MaxProtectedSpanLength), 10, 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, p.StableEnd(tt.text, tt.end))
+		})
+	}
+}
@@ -19,9 +19,16 @@
 package segmentation
 
 import (
+	"errors"
+	"fmt"
+
 	"github.com/yanmingcao/opencc-go/pkg/dict"
 )
 
+// ErrUnknownType is returned by NewSegmentation for an unknown segmentation
+// type
+var ErrUnknownType = errors.New("unknown segmentation type")
+
 // Segmentation interface for text segmentation strategies
 type Segmentation interface {
 	// Segment performs segmentation on the input text
@@ -50,11 +57,10 @@ func (s *MaxMatchSegmentation) Segment(text string) *Segments {
 
 	position := 0
 	textLength := len(text)
-	maxKeyLen := s.dict.KeyMaxLength()
 
 	for position < textLength {
 		// Try to find the longest match starting from current position
-		match := s.findLongestMatch(text, position, maxKeyLen)
+		match := s.findLongestMatch(text, position)
 
 		if match != nil {
 			// Found a match, add it and advance
@@ -65,8 +71,8 @@ func (s *MaxMatchSegmentation) Segment(text string) *Segments {
 			// No match found, advance by one character
 			// Find the length of the next UTF-8 character
 			charLen := s.nextUTF8CharLength(text, position)
-			if charLen == 0 {
-				// Invalid UTF-8, advance by one byte
+			if charLen == 0 || position+charLen > textLength {
+				// Invalid or truncated UTF-8, advance by one byte
 				charLen = 1
 			}
 			// Add the character as-is
@@ -80,25 +86,12 @@ func (s *MaxMatchSegmentation) Segment(text string) *Segments {
 }
 
 // findLongestMatch finds the longest matching prefix in the dictionary
-func (s *MaxMatchSegmentation) findLongestMatch(text string, start int, maxLen int) dict.DictEntry {
-	maxMatch := dict.DictEntry(nil)
-
-	// Try lengths from max to 1
-	for l := maxLen; l > 0; l-- {
-		if start+l > len(text) {
-			continue
-		}
-
-		prefix := text[start : start+l]
-		match := s.dict.Match(prefix)
-
-		if match != nil {
-			maxMatch = match
-			break // Found the longest, no need to continue
-		}
+func (s *MaxMatchSegmentation) findLongestMatch(text string, start int) dict.DictEntry {
+	match := s.dict.MatchPrefix(text[start:])
+	if match == nil || match.KeyLength() == 0 {
+		return nil
 	}
-
-	return maxMatch
+	return match
 }
 
 // nextUTF8CharLength returns the byte length of the next UTF-8 character
@@ -135,6 +128,14 @@ type SegmentationType string
 const (
 	// SegmentationTypeMMseg is maximum forward matching
 	SegmentationTypeMMseg SegmentationType = "mmseg"
+	// SegmentationTypeForward is maximum forward matching
+	SegmentationTypeForward SegmentationType = "fmm"
+	// SegmentationTypeBackward is maximum backward matching
+	SegmentationTypeBackward SegmentationType = "bmm"
+	// SegmentationTypeBidirectional is bidirectional maximum matching
+	SegmentationTypeBidirectional SegmentationType = "bimm"
+	// SegmentationTypeDAG is the most probable path through all matches
+	SegmentationTypeDAG SegmentationType = "dag"
 )
 
 // SegmentationConfig represents configuration for creating a segmentation
@@ -143,15 +144,34 @@ type SegmentationConfig struct {
 	Dict dict.Dict
 }
 
-// NewSegmentationFromConfig creates a segmentation from configuration
-func NewSegmentationFromConfig(config *SegmentationConfig) Segmentation {
-	switch config.Type {
-	case SegmentationTypeMMseg:
-		return NewMaxMatchSegmentation(config.Dict)
+// NewSegmentation creates a segmentation from configuration
+// An empty type selects forward maximum matching; an unknown type is an
+// error wrapping ErrUnknownType
+func NewSegmentation(cfg *SegmentationConfig) (Segmentation, error) {
+	switch cfg.Type {
+	case "", SegmentationTypeMMseg, SegmentationTypeForward:
+		return NewMaxMatchSegmentation(cfg.Dict), nil
+	case SegmentationTypeBackward:
+		return NewBackwardMaxMatchSegmentation(cfg.Dict), nil
+	case SegmentationTypeBidirectional:
+		return NewBidirectionalMaxMatchSegmentation(cfg.Dict), nil
+	case SegmentationTypeDAG:
+		return NewDAGSegmentation(cfg.Dict), nil
 	default:
-		// Default to maximum matching
-		return NewMaxMatchSegmentation(config.Dict)
+		return nil, fmt.Errorf("%w: %s", ErrUnknownType, cfg.Type)
+	}
+}
+
+// NewSegmentationFromConfig creates a segmentation from configuration
+// An unknown type falls back to forward maximum matching.
+//
+// Deprecated: use NewSegmentation, which reports unknown types.
+func NewSegmentationFromConfig(cfg *SegmentationConfig) Segmentation {
+	seg, err := NewSegmentation(cfg)
+	if err != nil {
+		return NewMaxMatchSegmentation(cfg.Dict)
 	}
+	return seg
 }
 
 // CharactersSegmentation performs character-by-character segmentation
@@ -170,8 +190,8 @@ func (s *CharactersSegmentation) Segment(text string) *Segments {
 	position := 0
 	for position < len(text) {
 		charLen := s.nextUTF8CharLength(text, position)
-		if charLen == 0 {
-			// Invalid UTF-8, skip one byte
+		if charLen == 0 || position+charLen > len(text) {
+			// Invalid or truncated UTF-8, skip one byte
 			charLen = 1
 		}
 		charStr := text[position : position+charLen]
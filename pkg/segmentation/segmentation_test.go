@@ -0,0 +1,47 @@
+/*
+ * Open Chinese Convert
+ *
+ * Copyright 2010-2014 Carbo Kuo <byvoid@byvoid.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package segmentation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSegmentation(t *testing.T) {
+	d := newWeightedDict(t, "研究\t研究\n")
+	for segType, expected := range map[SegmentationType]Segmentation{
+		"":                            &MaxMatchSegmentation{},
+		SegmentationTypeMMseg:         &MaxMatchSegmentation{},
+		SegmentationTypeForward:       &MaxMatchSegmentation{},
+		SegmentationTypeBackward:      &BackwardMaxMatchSegmentation{},
+		SegmentationTypeBidirectional: &BidirectionalMaxMatchSegmentation{},
+		SegmentationTypeDAG:           &DAGSegmentation{},
+	} {
+		seg, err := NewSegmentation(&SegmentationConfig{Type: segType, Dict: d})
+		require.NoError(t, err, segType)
+		assert.IsType(t, expected, seg, segType)
+		assert.IsType(t, expected, NewSegmentationFromConfig(&SegmentationConfig{Type: segType, Dict: d}), segType)
+	}
+
+	_, err := NewSegmentation(&SegmentationConfig{Type: "unknown", Dict: d})
+	assert.ErrorIs(t, err, ErrUnknownType)
+	assert.IsType(t, &MaxMatchSegmentation{}, NewSegmentationFromConfig(&SegmentationConfig{Type: "unknown", Dict: d}))
+}
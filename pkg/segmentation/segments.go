@@ -34,6 +34,8 @@ type Segments struct {
 		source  int
 		managed bool
 	}
+	// protected marks the positions of segments that must not be converted
+	protected map[int]bool
 }
 
 // NewSegments creates a new empty Segments
@@ -75,6 +77,21 @@ func (s *Segments) AddUnmanaged(str *string) {
 	s.unmanaged = append(s.unmanaged, str)
 }
 
+// AddProtected adds a segment that conversions pass through unchanged
+func (s *Segments) AddProtected(str string) {
+	if s.protected == nil {
+		s.protected = make(map[int]bool)
+	}
+	s.protected[len(s.indexes)] = true
+	s.AddManaged(str)
+}
+
+// IsProtected reports whether the segment at the given position must not
+// be converted
+func (s *Segments) IsProtected(pos int) bool {
+	return s.protected[pos]
+}
+
 // AddString adds a string as either managed or unmanaged
 func (s *Segments) AddString(str string, managed bool) {
 	if managed {
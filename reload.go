@@ -0,0 +1,366 @@
+/*
+ * Open Chinese Convert
+ *
+ * Copyright 2010-2014 Carbo Kuo <byvoid@byvoid.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package opencc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/yanmingcao/opencc-go/pkg/config"
+)
+
+// ErrFilesChanged is returned by a reload when the watched files changed
+// while the converter was being rebuilt; the next check retries
+var ErrFilesChanged = errors.New("files changed during reload")
+
+// reloadProbe is converted by every rebuilt converter before it is swapped
+// in, so that dictionaries that only fail on lookup are rejected
+const reloadProbe = "汉字漢字かな"
+
+// fileStamp is what polling compares to notice that a file changed
+type fileStamp struct {
+	modTime int64
+	size    int64
+	exists  bool
+}
+
+// reloadState is one version of the converter with the files it was built
+// from
+type reloadState struct {
+	converter *SimpleConverter
+	stamps    map[string]fileStamp
+	// users counts the conversions in progress and the converters handed
+	// out by Acquire that are not released yet
+	users atomic.Int64
+	// retired is set once a reload replaced the state; the converter is
+	// closed when it has no users left
+	retired atomic.Bool
+	closed  atomic.Bool
+}
+
+// release ends a use of the state
+func (s *reloadState) release() {
+	if s.users.Add(-1) == 0 && s.retired.Load() {
+		s.close()
+	}
+}
+
+// retire marks the state replaced, closing it if it is not in use
+func (s *reloadState) retire() {
+	s.retired.Store(true)
+	if s.users.Load() == 0 {
+		s.close()
+	}
+}
+
+// close closes the converter once
+func (s *reloadState) close() {
+	if s.closed.CompareAndSwap(false, true) {
+		s.converter.Close()
+	}
+}
+
+// ReloadableConverter converts with a configuration file and rebuilds the
+// converter when the configuration or its dictionaries change on disk. A
+// rebuilt converter is swapped in atomically: calls already in progress
+// finish on the version they started with.
+type ReloadableConverter struct {
+	configFilename string
+	searchPaths    []string
+	state          atomic.Pointer[reloadState]
+	// mu serializes reloads
+	mu sync.Mutex
+	// failed holds the stamps of the files a failed reload was built from,
+	// so that Check does not retry until they change again
+	failed map[string]fileStamp
+}
+
+// NewReloadableConverter creates a ReloadableConverter from a configuration
+// file, with the same search paths as NewSimpleConverter
+func NewReloadableConverter(configFilename string, searchPaths ...string) (*ReloadableConverter, error) {
+	r := &ReloadableConverter{
+		configFilename: configFilename,
+		searchPaths:    searchPaths,
+	}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Convert converts the input text with the current converter. The
+// dictionaries of a replaced converter are closed once the conversions
+// still using it finish.
+func (r *ReloadableConverter) Convert(text string) string {
+	state := r.acquire()
+	defer state.release()
+	return state.converter.Convert(text)
+}
+
+// Current returns the current converter without holding it open. A reload
+// that swaps in a newer one closes its compiled dictionaries, so it must
+// not be used after that; use Acquire to keep it usable.
+func (r *ReloadableConverter) Current() *SimpleConverter {
+	return r.state.Load().converter
+}
+
+// Acquire returns the current converter and a function that releases it.
+// The converter stays usable after a reload swaps in a newer one until it
+// is released, when its dictionaries are closed. release must be called
+// exactly once.
+func (r *ReloadableConverter) Acquire() (converter *SimpleConverter, release func()) {
+	state := r.acquire()
+	var once sync.Once
+	return state.converter, func() { once.Do(state.release) }
+}
+
+// acquire returns the current state for a use that ends with release
+func (r *ReloadableConverter) acquire() *reloadState {
+	for {
+		state := r.state.Load()
+		state.users.Add(1)
+		if !state.retired.Load() {
+			return state
+		}
+		// A reload replaced the state meanwhile
+		state.release()
+	}
+}
+
+// Files returns the files watched for changes: the configuration and the
+// dictionary and scheme files it resolves to. Embedded data is not watched,
+// but the paths where a file would be found first if created are, although
+// they are not listed.
+func (r *ReloadableConverter) Files() []string {
+	stamps := r.state.Load().stamps
+	var files []string
+	for _, file := range stampedFiles(stamps) {
+		if stamps[file].exists {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// Reload rebuilds the converter and swaps it in if it is valid. On error
+// the current converter is kept.
+func (r *ReloadableConverter) Reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, err := r.reload()
+	return err
+}
+
+// Check reloads the converter if any watched file changed since the last
+// reload and reports whether it did
+func (r *ReloadableConverter) Check() (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state := r.state.Load()
+	watched := state.stamps
+	if r.failed != nil {
+		watched = r.failed
+	}
+	if !changed(watched) {
+		return false, nil
+	}
+	stamps, err := r.reload()
+	if err != nil {
+		if !errors.Is(err, ErrFilesChanged) {
+			r.failed = stampFiles(stampedFiles(state.stamps))
+			maps.Copy(r.failed, stamps)
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// Watch checks the watched files every interval until ctx is done. Failed
+// reloads keep the current converter and are passed to onError, which may
+// be nil.
+func (r *ReloadableConverter) Watch(ctx context.Context, interval time.Duration, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := r.Check(); err != nil && onError != nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+// reload rebuilds and validates the converter, then swaps it in. It
+// returns the stamps of the files the converter was built from, as far as
+// they are known.
+func (r *ReloadableConverter) reload() (map[string]fileStamp, error) {
+	// Stamp the configuration before reading it, so that an edit made while
+	// reloading is noticed
+	configStamp := stampFile(r.configFilename)
+	cfg, paths, err := loadConfigFile(r.configFilename, r.searchPaths)
+	if err == nil {
+		err = cfg.Validate()
+	}
+	if err != nil {
+		stamps := map[string]fileStamp{r.configFilename: configStamp}
+		return stamps, fmt.Errorf("failed to reload %s: %w", r.configFilename, err)
+	}
+
+	files := configFiles(cfg, dictSearchPaths(paths))
+	stamps := stampFiles(files)
+	stamps[r.configFilename] = configStamp
+
+	converter, err := NewSimpleConverterFromConfig(cfg, paths...)
+	if err != nil {
+		return stamps, fmt.Errorf("failed to reload %s: %w", r.configFilename, err)
+	}
+	if err := probe(converter); err != nil {
+		converter.Close()
+		return stamps, fmt.Errorf("failed to reload %s: %w", r.configFilename, err)
+	}
+	if changed(stamps) {
+		converter.Close()
+		return stamps, fmt.Errorf("%w: %s", ErrFilesChanged, r.configFilename)
+	}
+
+	if old := r.state.Swap(&reloadState{converter: converter, stamps: stamps}); old != nil {
+		old.retire()
+	}
+	r.failed = nil
+	return stamps, nil
+}
+
+// probe converts reloadProbe, turning a panic into an error
+func probe(converter *SimpleConverter) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = fmt.Errorf("conversion failed: %v", v)
+		}
+	}()
+	converter.Convert(reloadProbe)
+	return nil
+}
+
+// configFiles returns the dictionary and scheme files that cfg resolves to
+// in searchPaths, in the same way the loaders resolve them
+func configFiles(cfg *config.Config, searchPaths []string) []string {
+	var files []string
+	if cfg.Segmentation != nil {
+		files = dictFiles(files, cfg.Segmentation.Dict, searchPaths)
+	}
+	for _, step := range cfg.ConversionChain {
+		files = dictFiles(files, step.Dict, searchPaths)
+		if step.Disambiguation != nil && step.Disambiguation.Scheme != "" {
+			files, _ = candidateFiles(files, step.Disambiguation.Scheme, schemeSearchPaths(searchPaths))
+		}
+	}
+	return files
+}
+
+// dictFiles appends the files that a dictionary configuration resolves to
+func dictFiles(files []string, cfg *config.DictConfig, searchPaths []string) []string {
+	if cfg == nil {
+		return files
+	}
+	if cfg.Type == "group" {
+		for _, d := range cfg.Dicts {
+			files = dictFiles(files, d, searchPaths)
+		}
+		return files
+	}
+
+	files, found := candidateFiles(files, cfg.File, searchPaths)
+	if found {
+		return files
+	}
+	// Stock binary dictionaries fall back to the text dictionary of the
+	// same name, see loadCompiledDict
+	if textName, ok := stockTextDict(cfg.File); ok && cfg.Type != "text" {
+		files, _ = candidateFiles(files, textName, searchPaths)
+	}
+	return files
+}
+
+// candidateFiles appends the paths that findFile tries for filename, up to
+// the one it finds, and reports whether it found one. The paths before it
+// are watched so that a file created there, which would shadow it, is
+// noticed.
+func candidateFiles(files []string, filename string, searchPaths []string) ([]string, bool) {
+	if filepath.IsAbs(filename) {
+		return append(files, filename), stampFile(filename).exists
+	}
+	for _, path := range searchPaths {
+		fullPath := filepath.Join(path, filename)
+		files = append(files, fullPath)
+		if stampFile(fullPath).exists {
+			return files, true
+		}
+	}
+	return files, false
+}
+
+// stampFile returns the current stamp of a file
+func stampFile(filename string) fileStamp {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return fileStamp{}
+	}
+	return fileStamp{modTime: info.ModTime().UnixNano(), size: info.Size(), exists: true}
+}
+
+// stampFiles returns the current stamps of the files
+func stampFiles(files []string) map[string]fileStamp {
+	stamps := make(map[string]fileStamp, len(files))
+	for _, file := range files {
+		stamps[file] = stampFile(file)
+	}
+	return stamps
+}
+
+// changed reports whether any of the stamped files changed since
+func changed(stamps map[string]fileStamp) bool {
+	for file, stamp := range stamps {
+		if stampFile(file) != stamp {
+			return true
+		}
+	}
+	return false
+}
+
+// stampedFiles returns the sorted names of the stamped files
+func stampedFiles(stamps map[string]fileStamp) []string {
+	files := make([]string, 0, len(stamps))
+	for file := range stamps {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	return files
+}
@@ -0,0 +1,206 @@
+/*
+ * Open Chinese Convert
+ *
+ * Copyright 2010-2014 Carbo Kuo <byvoid@byvoid.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package opencc
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yanmingcao/opencc-go/pkg/config"
+	"github.com/yanmingcao/opencc-go/pkg/dict"
+)
+
+const reloadTestConfig = `{
+  "name": "reload",
+  "segmentation": {"type": "mmseg", "dict": {"type": "text", "file": "reload_phrases.txt"}},
+  "conversion_chain": [{"dict": {"type": "text", "file": "reload_phrases.txt"}}]
+}`
+
+// writeReloadFile writes a file and moves its mtime forward, so that the
+// change is noticed however coarse the file system timestamps are
+func writeReloadFile(t *testing.T, filename, content string, age time.Duration) {
+	require.NoError(t, os.WriteFile(filename, []byte(content), 0644))
+	mtime := time.Now().Add(age)
+	require.NoError(t, os.Chtimes(filename, mtime, mtime))
+}
+
+func newReloadTestConverter(t *testing.T) (*ReloadableConverter, string) {
+	dir := t.TempDir()
+	writeReloadFile(t, filepath.Join(dir, "reload.json"), reloadTestConfig, -time.Hour)
+	writeReloadFile(t, filepath.Join(dir, "reload_phrases.txt"), "汉字\t漢字\n", -time.Hour)
+	r, err := NewReloadableConverter(filepath.Join(dir, "reload.json"))
+	require.NoError(t, err)
+	return r, dir
+}
+
+func TestReloadableConverter(t *testing.T) {
+	r, dir := newReloadTestConverter(t)
+	phrases := filepath.Join(dir, "reload_phrases.txt")
+	assert.Equal(t, []string{filepath.Join(dir, "reload.json"), phrases}, r.Files())
+	assert.Equal(t, "漢字简体", r.Convert("汉字简体"))
+
+	reloaded, err := r.Check()
+	require.NoError(t, err)
+	assert.False(t, reloaded)
+
+	old, release := r.Acquire()
+	defer release()
+	writeReloadFile(t, phrases, "汉字\t漢字\n简体\t簡體\n", 0)
+	reloaded, err = r.Check()
+	require.NoError(t, err)
+	assert.True(t, reloaded)
+	assert.Equal(t, "漢字簡體", r.Convert("汉字简体"))
+	// Converters handed out before the reload keep working unchanged
+	assert.Equal(t, "漢字简体", old.Convert("汉字简体"))
+
+	reloaded, err = r.Check()
+	require.NoError(t, err)
+	assert.False(t, reloaded)
+}
+
+func TestReloadableConverterNoticesShadowingFiles(t *testing.T) {
+	root := t.TempDir()
+	configDir := filepath.Join(root, "config")
+	dictDir := filepath.Join(root, "dictionary")
+	require.NoError(t, os.Mkdir(configDir, 0755))
+	require.NoError(t, os.Mkdir(dictDir, 0755))
+	writeReloadFile(t, filepath.Join(configDir, "reload.json"), reloadTestConfig, -time.Hour)
+	writeReloadFile(t, filepath.Join(dictDir, "reload_phrases.txt"), "汉字\t漢字\n", -time.Hour)
+	r, err := NewReloadableConverter(filepath.Join(configDir, "reload.json"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(configDir, "reload.json"), filepath.Join(dictDir, "reload_phrases.txt")}, r.Files())
+
+	// The config directory is searched before the dictionary directory
+	writeReloadFile(t, filepath.Join(configDir, "reload_phrases.txt"), "汉字\t汉字\n简体\t簡體\n", 0)
+	reloaded, err := r.Check()
+	require.NoError(t, err)
+	assert.True(t, reloaded)
+	assert.Equal(t, "汉字簡體", r.Convert("汉字简体"))
+}
+
+func TestReloadableConverterClosesReplaced(t *testing.T) {
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "reload.json")
+	binFile := filepath.Join(dir, "reload.bin")
+	writeBin := func(phrases string, age time.Duration) {
+		lexicon, err := dict.ParseLexiconFromReader(bufio.NewReader(strings.NewReader(phrases)))
+		require.NoError(t, err)
+		d, err := dict.NewCompiledDict(lexicon)
+		require.NoError(t, err)
+		// Mapped files are replaced, not rewritten
+		require.NoError(t, d.SerializeToFile(binFile+".tmp"))
+		mtime := time.Now().Add(age)
+		require.NoError(t, os.Chtimes(binFile+".tmp", mtime, mtime))
+		require.NoError(t, os.Rename(binFile+".tmp", binFile))
+	}
+	writeReloadFile(t, configFile, `{
+  "segmentation": {"type": "mmseg", "dict": {"type": "bin", "file": "reload.bin"}},
+  "conversion_chain": [{"dict": {"type": "bin", "file": "reload.bin"}}]
+}`, -time.Hour)
+	writeBin("汉字\t漢字\n", -time.Hour)
+	r, err := NewReloadableConverter(configFile)
+	require.NoError(t, err)
+	assert.Equal(t, "漢字简体", r.Convert("汉字简体"))
+
+	// A version only used by Convert is closed when it is replaced
+	replaced := r.state.Load()
+	writeBin("汉字\t漢字\n简体\t簡體\n", -time.Minute)
+	reloaded, err := r.Check()
+	require.NoError(t, err)
+	assert.True(t, reloaded)
+	assert.True(t, replaced.closed.Load())
+	assert.Equal(t, "漢字簡體", r.Convert("汉字简体"))
+
+	// One handed out by Acquire is closed once released
+	acquired := r.state.Load()
+	converter, release := r.Acquire()
+	require.NoError(t, r.Reload())
+	assert.False(t, acquired.closed.Load())
+	assert.Equal(t, "漢字簡體", converter.Convert("汉字简体"))
+	release()
+	release()
+	assert.True(t, acquired.closed.Load())
+	assert.Equal(t, int64(0), acquired.users.Load())
+
+	// Current does not hold the converter open
+	current := r.state.Load()
+	assert.Same(t, current.converter, r.Current())
+	require.NoError(t, r.Reload())
+	assert.True(t, current.closed.Load())
+}
+
+func TestReloadableConverterKeepsCurrentOnError(t *testing.T) {
+	r, dir := newReloadTestConverter(t)
+	configFile := filepath.Join(dir, "reload.json")
+	current := r.Current()
+
+	writeReloadFile(t, configFile, `{"segmentation": {"type": "unknown", "dict": {"type": "text", "file": "reload_phrases.txt"}}}`, 0)
+	reloaded, err := r.Check()
+	assert.ErrorIs(t, err, config.ErrUnknownSegType)
+	assert.False(t, reloaded)
+	assert.Same(t, current, r.Current())
+
+	// The failed version is not retried until the files change again
+	reloaded, err = r.Check()
+	require.NoError(t, err)
+	assert.False(t, reloaded)
+
+	writeReloadFile(t, configFile, `{"segmentation": {"type": "mmseg", "dict": {"type": "text", "file": "missing.txt"}}}`, time.Minute)
+	_, err = r.Check()
+	assert.Error(t, err)
+	assert.Same(t, current, r.Current())
+
+	writeReloadFile(t, configFile, reloadTestConfig, 2*time.Minute)
+	reloaded, err = r.Check()
+	require.NoError(t, err)
+	assert.True(t, reloaded)
+	assert.Equal(t, "漢字", r.Convert("汉字"))
+
+	_, err = NewReloadableConverter(filepath.Join(dir, "missing.json"))
+	assert.Error(t, err)
+}
+
+func TestReloadableConverterWatch(t *testing.T) {
+	r, dir := newReloadTestConverter(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		r.Watch(ctx, time.Millisecond, func(err error) {
+			if !errors.Is(err, ErrFilesChanged) {
+				t.Error(err)
+			}
+		})
+		close(done)
+	}()
+
+	writeReloadFile(t, filepath.Join(dir, "reload_phrases.txt"), "汉字\t汉字\n", 0)
+	assert.Eventually(t, func() bool { return r.Convert("汉字") == "汉字" }, 5*time.Second, time.Millisecond)
+
+	cancel()
+	<-done
+}
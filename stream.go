@@ -0,0 +1,159 @@
+/*
+ * Open Chinese Convert
+ *
+ * Copyright 2010-2014 Carbo Kuo <byvoid@byvoid.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package opencc
+
+import (
+	"context"
+	"io"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/yanmingcao/opencc-go/pkg/dict"
+	"github.com/yanmingcao/opencc-go/pkg/segmentation"
+)
+
+// streamChunkSize is the number of bytes read from the input at a time
+const streamChunkSize = 64 * 1024
+
+// ConvertStream converts everything read from r and writes the result to w.
+// The input is processed in chunks, and enough trailing bytes are carried
+// over between chunks that phrases and protected spans crossing a chunk
+// boundary are converted exactly as by Convert. Memory use does not depend
+// on the input size, except that a protected span is held in memory whole,
+// up to segmentation.MaxProtectedSpanLength bytes, and so is a line with segmentations other than forward maximum matching,
+// since they may segment the start of a line by what comes at its end.
+// Backward matching and DAG segmentation give the same result as Convert.
+// Bidirectional matching chooses between forward and backward matching for
+// every chunk of lines rather than for the whole input, so its result may
+// differ on input with more than one line.
+func (c *Converter) ConvertStream(ctx context.Context, r io.Reader, w io.Writer) error {
+	return c.convertStream(ctx, r, w, streamChunkSize)
+}
+
+// convertStream implements ConvertStream with the given chunk size
+func (c *Converter) convertStream(ctx context.Context, r io.Reader, w io.Writer, chunkSize int) error {
+	buf := make([]byte, 0, chunkSize+c.lookahead())
+	eof := false
+	for !eof {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		n, err := r.Read(buf[len(buf):cap(buf)])
+		buf = buf[:len(buf)+n]
+		if err == io.EOF {
+			eof = true
+		} else if err != nil {
+			return err
+		}
+		if !eof && len(buf) < cap(buf) {
+			continue
+		}
+
+		segments, consumed := c.stableSegments(string(buf), eof)
+		if segments.Length() > 0 {
+			result := c.conversionChain.Convert(segments)
+			if _, err := io.WriteString(w, result.ToString()); err != nil {
+				return err
+			}
+		}
+		buf = buf[:copy(buf, buf[consumed:])]
+		if len(buf) == cap(buf) {
+			// Nothing was stable, so the buffer must hold more
+			buf = slices.Grow(buf, cap(buf))
+		}
+	}
+	return nil
+}
+
+// lookahead returns the number of bytes after a position that can affect
+// how the text at that position is segmented
+func (c *Converter) lookahead() int {
+	n := utf8.UTFMax
+	if seg, ok := c.segmentation.(interface{ GetDict() dict.Dict }); ok {
+		n = max(n, seg.GetDict().KeyMaxLength())
+	}
+	return n
+}
+
+// segmentsLocally reports whether the segmentation at any position only
+// depends on the lookahead bytes after it. Other segmentations may depend
+// on the rest of the line.
+func (c *Converter) segmentsLocally() bool {
+	switch c.segmentation.(type) {
+	case *segmentation.MaxMatchSegmentation, *segmentation.CharactersSegmentation:
+		return true
+	}
+	return false
+}
+
+// stableSegments segments text and returns the segments that cannot change
+// when more text is appended, along with the number of bytes they cover.
+// If atEOF is set, all of text is segmented. No segment is returned if
+// nothing is stable yet, such as in a line longer than text for
+// segmentations that are not local or in a protected span that is not
+// complete.
+func (c *Converter) stableSegments(text string, atEOF bool) (*segmentation.Segments, int) {
+	if atEOF {
+		return c.segment(text), len(text)
+	}
+
+	end := len(text) - c.lookahead()
+	if !c.segmentsLocally() {
+		// Dictionary words never cross a line break, so complete lines are
+		// segmented independently of what follows
+		end = strings.LastIndexByte(text, '\n') + 1
+	}
+	if c.protector != nil && end > 0 {
+		end = c.protector.StableEnd(text, end)
+		if !c.segmentsLocally() {
+			end = strings.LastIndexByte(text[:end], '\n') + 1
+		}
+	}
+	return c.segmentsBefore(text, end)
+}
+
+// segmentsBefore segments text and returns the leading segments that end
+// at or before end, along with the number of bytes they cover
+func (c *Converter) segmentsBefore(text string, end int) (*segmentation.Segments, int) {
+	stable := segmentation.NewSegments()
+	if end <= 0 {
+		return stable, 0
+	}
+	segments := c.segment(text)
+	consumed := 0
+	for i := 0; i < segments.Length() && consumed+len(segments.At(i)) <= end; i++ {
+		if segments.IsProtected(i) {
+			stable.AddProtected(segments.At(i))
+		} else {
+			stable.AddManaged(segments.At(i))
+		}
+		consumed += len(segments.At(i))
+	}
+	return stable, consumed
+}
+
+// ConvertStream converts everything read from r and writes the result to w
+func (s *SimpleConverter) ConvertStream(ctx context.Context, r io.Reader, w io.Writer) error {
+	if s.auto != nil {
+		return s.auto.convertStream(ctx, r, w)
+	}
+	return s.converter.ConvertStream(ctx, r, w)
+}
@@ -0,0 +1,213 @@
+/*
+ * Open Chinese Convert
+ *
+ * Copyright 2010-2014 Carbo Kuo <byvoid@byvoid.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package opencc
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yanmingcao/opencc-go/pkg/conversion"
+	"github.com/yanmingcao/opencc-go/pkg/dict"
+	"github.com/yanmingcao/opencc-go/pkg/segmentation"
+)
+
+func newStreamTestConverter() *Converter {
+	lexicon := dict.NewLexicon()
+	lexicon.Add(dict.NewStrSingleValueDictEntry("简", "簡"))
+	lexicon.Add(dict.NewStrSingleValueDictEntry("简体", "簡體"))
+	lexicon.Add(dict.NewStrSingleValueDictEntry("简体字", "簡體字"))
+	lexicon.Add(dict.NewStrSingleValueDictEntry("体", "體"))
+	lexicon.Add(dict.NewStrSingleValueDictEntry("汉字", "漢字"))
+	lexicon.Add(dict.NewStrSingleValueDictEntry("字", "字"))
+	lexicon.Add(dict.NewStrSingleValueDictEntry("后", "後"))
+	lexicon.Add(dict.NewStrSingleValueDictEntry("皇后", "皇后"))
+	lexicon.Sort()
+	d := dict.NewTrieDict(lexicon)
+
+	seg := segmentation.NewMaxMatchSegmentation(d)
+	chain := conversion.NewConversionChain([]*conversion.Conversion{conversion.NewConversion(d)})
+	return NewConverter("test", seg, chain)
+}
+
+func TestConvertStream(t *testing.T) {
+	converter := newStreamTestConverter()
+	input := strings.Repeat("简体字与汉字，皇后之后。abc\n", 200)
+	expected := converter.Convert(input)
+
+	var out bytes.Buffer
+	require.NoError(t, converter.ConvertStream(context.Background(), strings.NewReader(input), &out))
+	assert.Equal(t, expected, out.String())
+
+	// Small chunks and one-byte reads split phrases and characters at
+	// every possible boundary
+	for chunkSize := 1; chunkSize <= 16; chunkSize++ {
+		out.Reset()
+		reader := iotest.OneByteReader(strings.NewReader(input))
+		require.NoError(t, converter.convertStream(context.Background(), reader, &out, chunkSize))
+		assert.Equal(t, expected, out.String(), "chunk size %d", chunkSize)
+	}
+}
+
+func TestConvertStreamTruncated(t *testing.T) {
+	converter := newStreamTestConverter()
+	input := "简体\xe5\xad"
+
+	var out bytes.Buffer
+	require.NoError(t, converter.convertStream(context.Background(), strings.NewReader(input), &out, 2))
+	assert.Equal(t, "簡體\xe5\xad", out.String())
+}
+
+func TestConvertStreamErrors(t *testing.T) {
+	converter := newStreamTestConverter()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var out bytes.Buffer
+	err := converter.ConvertStream(ctx, strings.NewReader("简体"), &out)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, out.String())
+
+	err = converter.ConvertStream(context.Background(), iotest.ErrReader(assert.AnError), &out)
+	assert.ErrorIs(t, err, assert.AnError)
+}
+
+func TestConvertStreamProtected(t *testing.T) {
+	converter := newStreamTestConverter()
+	converter.SetProtector(segmentation.NewDefaultProtector())
+
+	tests := map[string]string{
+		"fenced code":  "简体\n```\n简体代码\n汉字\n```\n简体",
+		"url":          "简体 https://example.com/简体/简体/简体 简体",
+		"comment":      "简体<!-- 简体\n汉字 -->简体",
+		"tag":          "简体<b\ntitle=\"简体汉字\">简体</b>",
+		"inline code":  "简体 `简体 汉字 简体` 简体",
+		"not a tag":    "简体 a<b 简体 汉字",
+		"unclosed tag": "简体 <b title=\"简体",
+	}
+	for name, text := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Move the text across every position of a chunk boundary
+			for offset := 0; offset < 24; offset++ {
+				input := strings.Repeat("a", offset) + text
+				expected := converter.Convert(input)
+				var out bytes.Buffer
+				require.NoError(t, converter.convertStream(context.Background(), strings.NewReader(input), &out, 8))
+				assert.Equal(t, expected, out.String(), "offset %d", offset)
+			}
+		})
+	}
+
+	// A URL ending just past the first chunk
+	input := strings.Repeat("a", streamChunkSize-3) + " https://example.com/简体/简体/简体"
+	var out bytes.Buffer
+	require.NoError(t, converter.ConvertStream(context.Background(), strings.NewReader(input), &out))
+	assert.True(t, converter.Convert(input) == out.String())
+}
+
+// countingReader counts the bytes read from it
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += n
+	return n, err
+}
+
+// progressWriter records how much input was read by the time the output
+// grew past mark bytes
+type progressWriter struct {
+	out   bytes.Buffer
+	input *countingReader
+	mark  int
+	read  int
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	n, err := w.out.Write(p)
+	if w.read == 0 && w.out.Len() > w.mark {
+		w.read = w.input.n
+	}
+	return n, err
+}
+
+func TestConvertStreamUnclosedProtected(t *testing.T) {
+	converter := newStreamTestConverter()
+	converter.SetProtector(segmentation.NewDefaultProtector())
+
+	body := strings.Repeat("简体汉字\n", 4*segmentation.MaxProtectedSpanLength/13)
+	for name, text := range map[string]string{
+		"fence":   "简体\n```\n" + body,
+		"comment": "简体<!-- " + body,
+	} {
+		t.Run(name, func(t *testing.T) {
+			input := &countingReader{r: strings.NewReader(text)}
+			out := &progressWriter{input: input, mark: len(text) / 4}
+			require.NoError(t, converter.ConvertStream(context.Background(), input, out))
+			assert.Less(t, out.read, len(text))
+			assert.True(t, converter.Convert(text) == out.out.String())
+		})
+	}
+}
+
+func TestConvertStreamSegmentations(t *testing.T) {
+	lexicon := dict.NewLexicon()
+	for _, word := range []string{"研究", "研究生", "生命", "命"} {
+		lexicon.Add(dict.NewStrSingleValueDictEntry(word, "["+word+"]"))
+	}
+	lexicon.Sort()
+	d := dict.NewTrieDict(lexicon)
+	chain := conversion.NewConversionChain([]*conversion.Conversion{conversion.NewConversion(d)})
+
+	segmentations := map[string]segmentation.Segmentation{
+		"backward":      segmentation.NewBackwardMaxMatchSegmentation(d),
+		"bidirectional": segmentation.NewBidirectionalMaxMatchSegmentation(d),
+		"dag":           segmentation.NewDAGSegmentation(d),
+	}
+	inputs := map[string]string{
+		"long line": strings.Repeat("研究生命", 50),
+		"lines":     strings.Repeat("研究生命\n", 50),
+	}
+	for name, seg := range segmentations {
+		converter := NewConverter(name, seg, chain)
+		for inputName, input := range inputs {
+			t.Run(name+"/"+inputName, func(t *testing.T) {
+				expected := converter.Convert(input)
+				if name == "backward" {
+					assert.Contains(t, expected, "[研究][生命]")
+				}
+				for chunkSize := 1; chunkSize <= 16; chunkSize++ {
+					var out bytes.Buffer
+					reader := iotest.OneByteReader(strings.NewReader(input))
+					require.NoError(t, converter.convertStream(context.Background(), reader, &out, chunkSize))
+					assert.Equal(t, expected, out.String(), "chunk size %d", chunkSize)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,76 @@
+/*
+ * Open Chinese Convert
+ *
+ * Copyright 2010-2014 Carbo Kuo <byvoid@byvoid.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package opencc
+
+import (
+	"golang.org/x/text/transform"
+)
+
+// transformerMaxPending is how much pending text the Transformer expects
+// its caller to buffer, the buffer size of transform.Reader and Writer
+const transformerMaxPending = 4096
+
+// Transformer adapts a Converter to golang.org/x/text/transform, so that
+// conversion can be chained with encoders and other transformers
+type Transformer struct {
+	transform.NopResetter
+	converter *Converter
+}
+
+// NewTransformer creates a new Transformer for the converter
+func NewTransformer(converter *Converter) *Transformer {
+	return &Transformer{converter: converter}
+}
+
+// Transformer returns a transform.Transformer that converts like the
+// SimpleConverter. For automatic presets it chooses a converter for every
+// paragraph.
+func (s *SimpleConverter) Transformer() transform.Transformer {
+	if s.auto != nil {
+		return &autoTransformer{auto: s.auto}
+	}
+	return NewTransformer(s.converter)
+}
+
+// Transform implements transform.Transformer. Text near the end of src that
+// could still be part of a longer phrase is left unconsumed and reported
+// with ErrShortSrc until more input arrives or atEOF is set. A line or
+// protected span that does not fit in transformerMaxPending bytes is cut as
+// forward maximum matching would cut it.
+func (t *Transformer) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	segments, consumed := t.converter.stableSegments(string(src), atEOF)
+	if consumed == 0 && !atEOF && len(src) >= transformerMaxPending {
+		segments, consumed = t.converter.segmentsBefore(string(src), len(src)-t.converter.lookahead())
+	}
+	converted := t.converter.conversionChain.Convert(segments)
+
+	for i := 0; i < segments.Length(); i++ {
+		output := converted.At(i)
+		if nDst+len(output) > len(dst) {
+			return nDst, nSrc, transform.ErrShortDst
+		}
+		nDst += copy(dst[nDst:], output)
+		nSrc += len(segments.At(i))
+	}
+
+	if consumed < len(src) {
+		return nDst, nSrc, transform.ErrShortSrc
+	}
+	return nDst, nSrc, nil
+}
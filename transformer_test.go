@@ -0,0 +1,106 @@
+/*
+ * Open Chinese Convert
+ *
+ * Copyright 2010-2014 Carbo Kuo <byvoid@byvoid.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package opencc
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding/traditionalchinese"
+	"golang.org/x/text/transform"
+
+	"github.com/yanmingcao/opencc-go/pkg/segmentation"
+)
+
+func TestTransformer(t *testing.T) {
+	converter := newStreamTestConverter()
+	input := strings.Repeat("简体字与汉字，皇后之后。abc\n", 500)
+	expected := converter.Convert(input)
+
+	result, n, err := transform.String(NewTransformer(converter), input)
+	require.NoError(t, err)
+	assert.Equal(t, len(input), n)
+	assert.Equal(t, expected, result)
+
+	reader := transform.NewReader(iotest.OneByteReader(strings.NewReader(input)), NewTransformer(converter))
+	output, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, expected, string(output))
+}
+
+func TestTransformerChain(t *testing.T) {
+	converter := newStreamTestConverter()
+	chain := transform.Chain(NewTransformer(converter), traditionalchinese.Big5.NewEncoder())
+
+	result, _, err := transform.String(chain, "简体汉字")
+	require.NoError(t, err)
+	decoded, _, err := transform.String(traditionalchinese.Big5.NewDecoder(), result)
+	require.NoError(t, err)
+	assert.Equal(t, "簡體漢字", decoded)
+}
+
+func TestTransformerShortBuffers(t *testing.T) {
+	tr := NewTransformer(newStreamTestConverter())
+	dst := make([]byte, 64)
+
+	// "简体" may still become "简体字", so nothing is consumed yet
+	nDst, nSrc, err := tr.Transform(dst, []byte("简体"), false)
+	assert.ErrorIs(t, err, transform.ErrShortSrc)
+	assert.Equal(t, 0, nDst)
+	assert.Equal(t, 0, nSrc)
+
+	nDst, nSrc, err = tr.Transform(dst, []byte("简体"), true)
+	require.NoError(t, err)
+	assert.Equal(t, "簡體", string(dst[:nDst]))
+	assert.Equal(t, len("简体"), nSrc)
+
+	nDst, nSrc, err = tr.Transform(dst[:4], []byte("汉字简体"), true)
+	assert.ErrorIs(t, err, transform.ErrShortDst)
+	assert.Equal(t, 0, nDst)
+	assert.Equal(t, 0, nSrc)
+
+	nDst, nSrc, err = tr.Transform(dst[:8], []byte("汉字简体"), true)
+	assert.ErrorIs(t, err, transform.ErrShortDst)
+	assert.Equal(t, "漢字", string(dst[:nDst]))
+	assert.Equal(t, len("汉字"), nSrc)
+}
+
+func TestTransformerProtected(t *testing.T) {
+	converter := newStreamTestConverter()
+	converter.SetProtector(segmentation.NewDefaultProtector())
+	// A code fence straddling the buffer of transform.Reader, and one too
+	// long to fit in it, which is cut rather than failing
+	inputs := []string{
+		strings.Repeat("a", transformerMaxPending-10) + "\n```\n简体\n```\n简体",
+		"简体\n```\n" + strings.Repeat("简体\n", transformerMaxPending) + "```\n",
+	}
+	for i, input := range inputs {
+		output, err := io.ReadAll(transform.NewReader(strings.NewReader(input), NewTransformer(converter)))
+		require.NoError(t, err)
+		if i == 0 {
+			assert.Equal(t, converter.Convert(input), string(output))
+		} else {
+			assert.Equal(t, len(converter.Convert(input)), len(output))
+		}
+	}
+}
@@ -0,0 +1,130 @@
+/*
+ * Open Chinese Convert
+ *
+ * Copyright 2010-2014 Carbo Kuo <byvoid@byvoid.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package opencc
+
+import (
+	"io"
+	"os"
+	"sort"
+
+	"github.com/yanmingcao/opencc-go/pkg/conversion"
+	"github.com/yanmingcao/opencc-go/pkg/dict"
+	"github.com/yanmingcao/opencc-go/pkg/segmentation"
+)
+
+// userDict is a dictionary layered over the preset dictionaries
+type userDict struct {
+	dict     dict.Dict
+	priority int
+}
+
+// WithUserDict returns a converter that also consults d, both to segment
+// the text and in the first step of the conversion chain, so custom phrases
+// are kept whole and converted as given. Dictionaries with a higher priority
+// are consulted first. The preset dictionaries have priority 0 and win ties,
+// so a positive priority overrides them and any other priority only fills
+// in words they lack. The receiver is not modified.
+func (s *SimpleConverter) WithUserDict(d dict.Dict, priority int) *SimpleConverter {
+	if s.auto != nil {
+		return s.auto.withConverters(func(c *SimpleConverter) *SimpleConverter {
+			return c.WithUserDict(d, priority)
+		}).simpleConverter()
+	}
+
+	base := s.base
+	if base == nil {
+		base = s.converter
+	}
+	userDicts := append(append([]userDict(nil), s.userDicts...), userDict{dict: d, priority: priority})
+	sort.SliceStable(userDicts, func(i, j int) bool {
+		return userDicts[i].priority > userDicts[j].priority
+	})
+
+	converter := NewConverter(base.name, withSegmentationDict(base.segmentation, userDicts), withFirstStepDict(base.conversionChain, userDicts))
+	converter.SetProtector(s.converter.protector)
+	return &SimpleConverter{converter: converter, base: base, userDicts: userDicts}
+}
+
+// layerDicts groups the user dictionaries, sorted by priority, around the
+// preset dictionary d
+func layerDicts(d dict.Dict, userDicts []userDict) dict.Dict {
+	dicts := make([]dict.Dict, 0, len(userDicts)+1)
+	for _, u := range userDicts {
+		if u.priority > 0 {
+			dicts = append(dicts, u.dict)
+		}
+	}
+	dicts = append(dicts, d)
+	for _, u := range userDicts {
+		if u.priority <= 0 {
+			dicts = append(dicts, u.dict)
+		}
+	}
+	return dict.NewDictGroup(dicts)
+}
+
+// withSegmentationDict returns a segmentation of the same type as seg over
+// its dictionary layered with the user dictionaries. Segmentations without
+// a dictionary are returned unchanged.
+func withSegmentationDict(seg segmentation.Segmentation, userDicts []userDict) segmentation.Segmentation {
+	switch s := seg.(type) {
+	case *segmentation.MaxMatchSegmentation:
+		return segmentation.NewMaxMatchSegmentation(layerDicts(s.GetDict(), userDicts))
+	case *segmentation.BackwardMaxMatchSegmentation:
+		return segmentation.NewBackwardMaxMatchSegmentation(layerDicts(s.GetDict(), userDicts))
+	case *segmentation.BidirectionalMaxMatchSegmentation:
+		return segmentation.NewBidirectionalMaxMatchSegmentation(layerDicts(s.GetDict(), userDicts))
+	case *segmentation.DAGSegmentation:
+		return segmentation.NewDAGSegmentation(layerDicts(s.GetDict(), userDicts))
+	default:
+		return seg
+	}
+}
+
+// withFirstStepDict returns a copy of chain whose first step consults the
+// user dictionaries as well
+func withFirstStepDict(chain *conversion.ConversionChain, userDicts []userDict) *conversion.ConversionChain {
+	conversions := append([]*conversion.Conversion(nil), chain.GetConversions()...)
+	if len(conversions) > 0 {
+		first := conversion.NewConversion(layerDicts(conversions[0].GetDict(), userDicts))
+		first.SetDisambiguator(conversions[0].GetDisambiguator())
+		conversions[0] = first
+	}
+	return conversion.NewConversionChain(conversions)
+}
+
+// LoadUserDict loads a text dictionary file, in the same format as the
+// preset dictionaries, for use with WithUserDict
+func LoadUserDict(filename string) (dict.Dict, error) {
+	return loadTextDictFile(filename)
+}
+
+// loadTextDictFile loads a text dictionary file into a TrieDict that
+// records where each entry is defined
+func loadTextDictFile(path string) (dict.Dict, error) {
+	lexicon, err := dict.ParseLexiconFromFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	lexicon.Sort()
+	return dict.NewSourceDict(dict.NewTrieDict(lexicon), path, func() (io.ReadCloser, error) {
+		return os.Open(path)
+	}), nil
+}
@@ -0,0 +1,80 @@
+/*
+ * Open Chinese Convert
+ *
+ * Copyright 2010-2014 Carbo Kuo <byvoid@byvoid.com>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package opencc
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yanmingcao/opencc-go/pkg/dict"
+	"github.com/yanmingcao/opencc-go/pkg/segmentation"
+)
+
+func newUserDict(entries ...string) dict.Dict {
+	lexicon := dict.NewLexicon()
+	for i := 0; i+1 < len(entries); i += 2 {
+		lexicon.Add(dict.NewStrSingleValueDictEntry(entries[i], entries[i+1]))
+	}
+	lexicon.Sort()
+	return dict.NewTrieDict(lexicon)
+}
+
+func TestWithUserDict(t *testing.T) {
+	converter := &SimpleConverter{converter: newStreamTestConverter()}
+	assert.Equal(t, "漢字體，簡體", converter.Convert("汉字体，简体"))
+
+	// The user phrase is kept whole by segmentation and converted as given
+	custom := converter.WithUserDict(newUserDict("汉字体", "漢字体", "简体", "简体"), 1)
+	assert.Equal(t, "漢字体，简体", custom.Convert("汉字体，简体"))
+	assert.Equal(t, "漢字體，簡體", converter.Convert("汉字体，简体"))
+	assert.IsType(t, &segmentation.MaxMatchSegmentation{}, custom.GetConverter().GetSegmentation())
+
+	// The preset wins ties and only gaps are filled
+	fallback := converter.WithUserDict(newUserDict("汉字体", "漢字体", "简体", "简体"), 0)
+	assert.Equal(t, "漢字体，簡體", fallback.Convert("汉字体，简体"))
+
+	// Higher priorities are consulted first, whatever the order added
+	layered := custom.WithUserDict(newUserDict("简体", "简体字"), 2)
+	assert.Equal(t, "漢字体，简体字", layered.Convert("汉字体，简体"))
+	layered = converter.WithUserDict(newUserDict("简体", "简体字"), 2).WithUserDict(newUserDict("简体", "简体"), 1)
+	assert.Equal(t, "简体字", layered.Convert("简体"))
+
+	converter.SetProtector(segmentation.NewProtector())
+	assert.NotNil(t, converter.WithUserDict(newUserDict(), 1).GetConverter().GetProtector())
+}
+
+func TestLoadUserDict(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "user.txt")
+	require.NoError(t, os.WriteFile(filename, []byte("# products\n汉字体\t漢字体\n"), 0644))
+	d, err := LoadUserDict(filename)
+	require.NoError(t, err)
+
+	converter := (&SimpleConverter{converter: newStreamTestConverter()}).WithUserDict(d, 1)
+	explanations := converter.Explain("汉字体")
+	require.Len(t, explanations, 1)
+	assert.Equal(t, "漢字体", explanations[0].Target)
+	assert.Equal(t, filename+":2", explanations[0].Steps[0].Source.String())
+
+	_, err = LoadUserDict(filepath.Join(t.TempDir(), "missing.txt"))
+	assert.Error(t, err)
+}